@@ -5,15 +5,19 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
 	"syscall"
 
 	"finagent/internal/agent"
 	"finagent/internal/agent/tools"
+	"finagent/internal/imageprep"
+	"finagent/internal/scheduler"
+	"finagent/internal/sessionstore"
 	"finagent/internal/telegram"
 
 	"github.com/joho/godotenv"
 	"google.golang.org/adk/runner"
-	"google.golang.org/adk/session"
 )
 
 func main() {
@@ -35,13 +39,37 @@ func main() {
 		log.Fatalf("❌ Failed to create tools: %v", err)
 	}
 
-	trackerAgent, err := agent.NewTrackerAgent(ctx, adkTools)
+	// The model is shared with the summarizer that compacts long sessions
+	llm, err := agent.NewGeminiModel(ctx)
+	if err != nil {
+		log.Fatalf("❌ Failed to create model: %v", err)
+	}
+
+	// Read-only users only see the query tools
+	trackerAgent, err := agent.NewTrackerAgentWithOptions(ctx, adkTools, agent.Options{
+		Model:      llm,
+		ToolFilter: telegram.ToolFilter,
+	})
 	if err != nil {
 		log.Fatalf("❌ Failed to create agent: %v", err)
 	}
 
-	// Create session service and runner
-	sessionService := session.InMemoryService()
+	// Initialize bot components
+	config := telegram.DefaultConfig()
+	config.WebhookListen = os.Getenv("TELEGRAM_WEBHOOK_LISTEN")
+	config.WebhookURL = os.Getenv("TELEGRAM_WEBHOOK_URL")
+	config.WebhookSecret = os.Getenv("TELEGRAM_WEBHOOK_SECRET")
+	config.EnableGroups, _ = strconv.ParseBool(os.Getenv("TELEGRAM_ENABLE_GROUPS"))
+	config.Image = imageprep.OptionsFromEnv()
+	if dir := os.Getenv("SESSION_DIR"); dir != "" {
+		config.SessionDir = dir
+	}
+
+	// Create session service and runner; sessions are journaled to disk
+	sessionService, err := sessionstore.NewFileService(ctx, config.SessionDir)
+	if err != nil {
+		log.Fatalf("❌ Failed to load sessions: %v", err)
+	}
 	runnerInst, err := runner.New(runner.Config{
 		AppName:        "financial_tracker",
 		Agent:          trackerAgent,
@@ -51,9 +79,6 @@ func main() {
 		log.Fatalf("❌ Failed to create runner: %v", err)
 	}
 
-	// Initialize bot components
-	config := telegram.DefaultConfig()
-
 	// Ensure directories exist
 	if err := os.MkdirAll(config.LogDir, 0o755); err != nil {
 		log.Fatalf("❌ Failed to create log directory: %v", err)
@@ -65,23 +90,64 @@ func main() {
 	log.Printf("📁 Using temp directory: %s", config.PhotoTempDir)
 	log.Printf("📁 Using log directory: %s", config.LogDir)
 
+	owners, err := telegram.ParseUserIDs(os.Getenv("TELEGRAM_OWNER_IDS"))
+	if err != nil {
+		log.Fatalf("❌ Invalid TELEGRAM_OWNER_IDS: %v", err)
+	}
+	if len(owners) == 0 {
+		log.Println("⚠️  TELEGRAM_OWNER_IDS is empty, only existing members can use the bot")
+	}
+
+	access, err := telegram.LoadAccessList(config.AccessPath, owners)
+	if err != nil {
+		log.Fatalf("❌ Failed to load access list: %v", err)
+	}
+
 	logger := telegram.NewToolLogger(config.LogDir)
 	botRunner := telegram.NewBotRunner(runnerInst, sessionService, logger)
+	if err := botRunner.LoadSessions(ctx, filepath.Join(config.SessionDir, "chats.json")); err != nil {
+		log.Fatalf("❌ Failed to restore chat sessions: %v", err)
+	}
+	botRunner.SetSessionPolicy(config.Session, agent.NewSummarizer(llm))
+	botRunner.SetRateLimit(config.RateLimit)
+	botRunner.SetImageOptions(config.Image)
 
-	bot, err := telegram.NewTelegramBot(token, botRunner, config)
+	bot, err := telegram.NewTelegramBot(token, botRunner, access, config)
 	if err != nil {
 		log.Fatalf("❌ Failed to create telegram bot: %v", err)
 	}
 
-	// Handle graceful shutdown
-	sigChan := make(chan os.Signal, 1)
+	// Digests are pushed on per-chat schedules stored next to the sessions
+	sched, err := scheduler.Load(config.SchedulePath, scheduler.RealClock())
+	if err != nil {
+		log.Fatalf("❌ Failed to load schedules: %v", err)
+	}
+	bot.SetScheduler(sched)
+
+	// Optional: transcribe voice notes with Whisper instead of sending audio
+	if url := os.Getenv("WHISPER_URL"); url != "" {
+		bot.SetTranscriber(telegram.NewWhisperTranscriber(url, os.Getenv("WHISPER_API_KEY")))
+		log.Printf("🎙️ Voice notes are transcribed by %s", url)
+	}
+
+	// Handle graceful shutdown: stop taking updates, let running messages
+	// finish, then clean up. A second signal exits immediately.
+	sigChan := make(chan os.Signal, 2)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	stopped := make(chan struct{})
 	go func() {
 		<-sigChan
 		log.Println("\n🛑 Shutting down bot...")
+		go func() {
+			<-sigChan
+			log.Println("🛑 Forced exit")
+			os.Exit(1)
+		}()
+
+		bot.Shutdown(config.ShutdownTimeout)
 		bot.Cleanup()
-		os.Exit(0)
+		close(stopped)
 	}()
 
 	// Start bot
@@ -89,4 +155,6 @@ func main() {
 	if err := bot.Start(); err != nil {
 		log.Fatalf("❌ Bot failed: %v", err)
 	}
+	<-stopped
+	log.Println("👋 Bot stopped")
 }
@@ -6,11 +6,15 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"finagent/internal/agent"
 	"finagent/internal/agent/tools"
 	"finagent/internal/cli"
+	"finagent/internal/imageprep"
+	"finagent/internal/replay"
+	"finagent/internal/sessionstore"
 
 	"github.com/joho/godotenv"
 	"google.golang.org/adk/runner"
@@ -29,12 +33,37 @@ func main() {
 		log.Fatalf("Failed to create tools: %v", err)
 	}
 
-	trackerAgent, err := agent.NewTrackerAgent(ctx, adkTools)
+	// RECORD_FIXTURE=path records the session for replay in tests
+	var recorder *replay.Recorder
+	agentOpts := agent.Options{}
+	if fixturePath := os.Getenv("RECORD_FIXTURE"); fixturePath != "" {
+		model, err := agent.NewGeminiModel(ctx)
+		if err != nil {
+			log.Fatalf("Failed to create model: %v", err)
+		}
+
+		recorder = replay.NewRecorder(strings.TrimSuffix(filepath.Base(fixturePath), filepath.Ext(fixturePath)))
+		agentOpts.Model = recorder.WrapModel(model)
+		agentOpts.BeforeAgentCallbacks = append(agentOpts.BeforeAgentCallbacks, recorder.BeforeAgent)
+		agentOpts.AfterToolCallbacks = append(agentOpts.AfterToolCallbacks, recorder.AfterTool)
+		fmt.Println(cli.Gray(fmt.Sprintf("Recording session to %s", fixturePath)))
+	}
+
+	trackerAgent, err := agent.NewTrackerAgentWithOptions(ctx, adkTools, agentOpts)
 	if err != nil {
 		log.Fatalf("Failed to create agent: %v", err)
 	}
 
-	sessionService := session.InMemoryService()
+	// Sessions are journaled to SESSION_DIR (default: data/sessions)
+	sessionDir := os.Getenv("SESSION_DIR")
+	if sessionDir == "" {
+		sessionDir = "data/sessions"
+	}
+	sessionService, err := sessionstore.NewFileService(ctx, sessionDir)
+	if err != nil {
+		log.Fatalf("Failed to load sessions: %v", err)
+	}
+
 	runner, err := runner.New(runner.Config{
 		AppName:        "financial_tracker",
 		Agent:          trackerAgent,
@@ -44,21 +73,62 @@ func main() {
 		log.Fatalf("Failed to create runner: %v", err)
 	}
 
-	sess, err := sessionService.Create(ctx, &session.CreateRequest{
-		AppName: "financial_tracker",
-		UserID:  "user_cli",
-	})
-	if err != nil {
-		log.Fatalf("Failed to create session: %v", err)
+	// Continue the last conversation unless NEW_SESSION is set; recordings
+	// always start fresh so the fixture holds the whole conversation
+	sessionID := ""
+	if os.Getenv("NEW_SESSION") == "" && recorder == nil {
+		sessionID = latestSession(ctx, sessionService, "user_cli")
+	}
+	if sessionID == "" {
+		sess, err := sessionService.Create(ctx, &session.CreateRequest{
+			AppName: "financial_tracker",
+			UserID:  "user_cli",
+		})
+		if err != nil {
+			log.Fatalf("Failed to create session: %v", err)
+		}
+		sessionID = sess.Session.ID()
+	} else {
+		fmt.Println(cli.Gray(fmt.Sprintf("Resuming session %s (NEW_SESSION=1 starts a new one)", sessionID)))
 	}
 
-	cliRunner := cli.NewCLIRunner(runner, sess.Session.ID(), "user_cli")
+	cliRunner := cli.NewCLIRunner(runner, sessionService, sessionID, "user_cli")
+	cliRunner.SetImageOptions(imageprep.OptionsFromEnv())
 
 	fmt.Println(cli.Cyan("=== Financial Tracker Agent CLI ==="))
 	fmt.Println(cli.Gray("Type 'exit' to quit"))
 	fmt.Println(cli.Gray("For images: leave text empty and provide image path\n"))
 
 	scanner := bufio.NewScanner(os.Stdin)
+
+	// Mutations only happen after an explicit answer here. Returns false
+	// when stdin is closed.
+	confirmPending := func() bool {
+		pending, err := cliRunner.PendingActions(ctx)
+		if err != nil {
+			fmt.Printf("%s\n", cli.Red(fmt.Sprintf("Error: %v", err)))
+			return true
+		}
+		for _, action := range pending {
+			fmt.Printf("\n%s %s\n", cli.Yellow("⏳ Confirm:"), action.Summary)
+			fmt.Print(cli.Blue("[y/N]> "))
+			if !scanner.Scan() {
+				return false
+			}
+			answer := strings.ToLower(strings.TrimSpace(scanner.Text()))
+			approve := answer == "y" || answer == "yes"
+			if err := cliRunner.ResolveAction(ctx, action.ID, approve); err != nil {
+				fmt.Printf("%s\n", cli.Red(fmt.Sprintf("Error: %v", err)))
+			}
+		}
+		return true
+	}
+
+	// A resumed session may still wait for a confirmation
+	if !confirmPending() {
+		return
+	}
+
 	for {
 		fmt.Print(cli.Blue("> "))
 		if !scanner.Scan() {
@@ -85,5 +155,34 @@ func main() {
 		if err := cliRunner.Run(ctx, text, imagePath); err != nil {
 			fmt.Printf("%s\n", cli.Red(fmt.Sprintf("Error: %v", err)))
 		}
+
+		if !confirmPending() {
+			break
+		}
+	}
+
+	if recorder != nil {
+		fixturePath := os.Getenv("RECORD_FIXTURE")
+		if err := recorder.Save(fixturePath); err != nil {
+			log.Fatalf("Failed to save fixture: %v", err)
+		}
+		fmt.Println(cli.Green(fmt.Sprintf("✓ Fixture saved to %s", fixturePath)))
+	}
+}
+
+// latestSession returns the most recently updated session of userID, or ""
+// if there is none.
+func latestSession(ctx context.Context, svc session.Service, userID string) string {
+	resp, err := svc.List(ctx, &session.ListRequest{AppName: "financial_tracker", UserID: userID})
+	if err != nil || len(resp.Sessions) == 0 {
+		return ""
+	}
+
+	latest := resp.Sessions[0]
+	for _, sess := range resp.Sessions[1:] {
+		if sess.LastUpdateTime().After(latest.LastUpdateTime()) {
+			latest = sess
+		}
 	}
+	return latest.ID()
 }
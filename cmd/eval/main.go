@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"finagent/internal/agent"
+	"finagent/internal/eval"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	dir := flag.String("dir", "data/img", "directory with receipt images and golden .json files")
+	modelName := flag.String("model", os.Getenv("GEMINI_MODEL"), "Gemini model to evaluate")
+	promptPath := flag.String("prompt", "", "file with an alternative system prompt (default: built-in SystemPrompt)")
+	outPath := flag.String("out", "", "write the full report as JSON to this file")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	cases, skipped, err := eval.LoadCases(*dir)
+	if err != nil {
+		log.Fatalf("Failed to load cases: %v", err)
+	}
+	for _, name := range skipped {
+		log.Printf("⚠ Skipping %s: no golden .json file", name)
+	}
+	if len(cases) == 0 {
+		log.Fatalf("No eval cases found in %s", *dir)
+	}
+
+	// -model overrides GEMINI_MODEL for the agent's model
+	os.Setenv("GEMINI_MODEL", *modelName)
+	model, err := agent.NewGeminiModel(ctx)
+	if err != nil {
+		log.Fatalf("Failed to create model: %v", err)
+	}
+
+	extractor := eval.NewExtractor(model)
+	promptLabel := "built-in"
+	if *promptPath != "" {
+		data, err := os.ReadFile(*promptPath)
+		if err != nil {
+			log.Fatalf("Failed to read prompt: %v", err)
+		}
+		extractor.Instruction = string(data)
+		promptLabel = *promptPath
+	}
+
+	report := &eval.Report{
+		Model:     *modelName,
+		Prompt:    promptLabel,
+		StartedAt: time.Now(),
+	}
+	report.Results = extractor.Run(ctx, cases, os.Stderr)
+	report.Summarize()
+
+	fmt.Println()
+	report.Print(os.Stdout)
+
+	if *outPath != "" {
+		if err := report.Save(*outPath); err != nil {
+			log.Fatalf("Failed to save report: %v", err)
+		}
+		fmt.Printf("\n✓ Report saved to %s\n", *outPath)
+	}
+}
@@ -0,0 +1,67 @@
+package hitl
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"finagent/internal/agent/tools"
+
+	"google.golang.org/adk/tool"
+)
+
+// Gate is a BeforeToolCallback that intercepts mutating tools. Instead of
+// running the tool it records a PendingAction in session state and returns
+// a "pending_confirmation" result to the model.
+func Gate(ctx tool.Context, t tool.Tool, args map[string]any) (map[string]any, error) {
+	if !MutatingTools[t.Name()] {
+		return nil, nil
+	}
+
+	if t.Name() == "append_to_sheet" {
+		if err := validateAppend(args); err != nil {
+			return map[string]any{"status": "error", "error": err.Error()}, nil
+		}
+	}
+
+	var action *PendingAction
+	for _, pending := range Pending(ctx.State()) {
+		if sameCall(pending, t.Name(), args) {
+			action = &pending
+			break
+		}
+	}
+
+	if action == nil {
+		action = &PendingAction{
+			ID:        newActionID(),
+			Tool:      t.Name(),
+			Args:      args,
+			Summary:   describe(t.Name(), args),
+			AddedBy:   tools.AuthorFrom(ctx),
+			CreatedAt: time.Now(),
+		}
+		if err := ctx.State().Set(StateKey(action.ID), encode(*action)); err != nil {
+			return nil, fmt.Errorf("failed to store pending action: %w", err)
+		}
+	}
+
+	return map[string]any{
+		"status":   "pending_confirmation",
+		"actionId": action.ID,
+		"message": fmt.Sprintf("%s is waiting for user confirmation. Nothing has been saved yet. "+
+			"The user confirms or cancels it with the app's confirmation prompt; do not call the tool again.", action.Summary),
+	}, nil
+}
+
+func validateAppend(args map[string]any) error {
+	raw, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("invalid args: %w", err)
+	}
+	var appendArgs tools.AppendSheetArgs
+	if err := json.Unmarshal(raw, &appendArgs); err != nil {
+		return fmt.Errorf("invalid args: %w", err)
+	}
+	return tools.ValidateRows(appendArgs.Values)
+}
@@ -0,0 +1,142 @@
+// Package hitl enforces human-in-the-loop confirmation for mutating tools.
+//
+// A mutating tool call never runs directly. Gate turns it into a
+// PendingAction stored in session state and tells the model it is waiting
+// for the user. Only Resolve or Decide, called by the interface after an
+// explicit user decision (CLI prompt, Telegram button), executes or
+// discards it.
+package hitl
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"finagent/internal/agent/tools"
+
+	"google.golang.org/adk/session"
+)
+
+// StatePrefix starts the session state key of each pending action,
+// "pending_actions:<id>", holding the JSON encoded PendingAction. One key
+// per action keeps parallel tool calls from overwriting each other: ADK
+// merges their state deltas key by key. A resolved action's key is "".
+const StatePrefix = "pending_actions:"
+
+// StateKey returns the session state key of an action.
+func StateKey(actionID string) string {
+	return StatePrefix + actionID
+}
+
+// MutatingTools are the tools that require confirmation.
+var MutatingTools = map[string]bool{
+	"append_to_sheet":    true,
+	"write_to_sheet":     true,
+	"create_new_sheet":   true,
+	"add_merchant_alias": true,
+}
+
+type PendingAction struct {
+	ID        string         `json:"id"`
+	Tool      string         `json:"tool"`
+	Args      map[string]any `json:"args"`
+	Summary   string         `json:"summary"`
+	AddedBy   string         `json:"addedBy,omitempty"` // who sent the message, for added_by
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// Pending returns the actions waiting for confirmation in a session state,
+// oldest first.
+func Pending(state session.ReadonlyState) []PendingAction {
+	var actions []PendingAction
+	for key, val := range state.All() {
+		raw, ok := val.(string)
+		if !strings.HasPrefix(key, StatePrefix) || !ok || raw == "" {
+			continue
+		}
+		var action PendingAction
+		if err := json.Unmarshal([]byte(raw), &action); err != nil {
+			continue
+		}
+		actions = append(actions, action)
+	}
+
+	sort.Slice(actions, func(i, j int) bool {
+		if !actions[i].CreatedAt.Equal(actions[j].CreatedAt) {
+			return actions[i].CreatedAt.Before(actions[j].CreatedAt)
+		}
+		return actions[i].ID < actions[j].ID
+	})
+	return actions
+}
+
+// PendingState returns the state entries of the pending actions, to carry
+// them over into a new session.
+func PendingState(state session.ReadonlyState) map[string]any {
+	entries := make(map[string]any)
+	for _, action := range Pending(state) {
+		entries[StateKey(action.ID)] = encode(action)
+	}
+	return entries
+}
+
+func encode(action PendingAction) string {
+	data, _ := json.Marshal(action)
+	return string(data)
+}
+
+func newActionID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return "act_" + hex.EncodeToString(b)
+}
+
+// sameCall reports whether two calls are the same tool with identical args,
+// so a repeated proposal reuses the existing action instead of duplicating it.
+func sameCall(a PendingAction, tool string, args map[string]any) bool {
+	if a.Tool != tool {
+		return false
+	}
+	x, _ := json.Marshal(a.Args)
+	y, _ := json.Marshal(args)
+	return string(x) == string(y)
+}
+
+// describe builds the one-line summary shown to the user.
+func describe(tool string, args map[string]any) string {
+	sheetName, _ := args["sheetName"].(string)
+	rows, _ := args["values"].([]any)
+
+	switch tool {
+	case "append_to_sheet":
+		total := 0.0
+		for _, r := range rows {
+			row, ok := r.([]any)
+			if !ok || len(row) <= 5 {
+				continue
+			}
+			var amount float64
+			fmt.Sscanf(fmt.Sprintf("%v", row[5]), "%g", &amount)
+			total += amount
+		}
+		if total > 0 {
+			return fmt.Sprintf("Append %d rows to '%s' (total %s)", len(rows), sheetName, tools.FormatRupiah(total))
+		}
+		return fmt.Sprintf("Append %d rows to '%s'", len(rows), sheetName)
+	case "write_to_sheet":
+		rangeNotation, _ := args["rangeNotation"].(string)
+		return fmt.Sprintf("Overwrite %s!%s with %d rows", sheetName, rangeNotation, len(rows))
+	case "create_new_sheet":
+		title, _ := args["sheetTitle"].(string)
+		return fmt.Sprintf("Create sheet 'Transaction_%s_%s'", title, time.Now().Format("20060102"))
+	case "add_merchant_alias":
+		alias, _ := args["alias"].(string)
+		merchant, _ := args["merchant"].(string)
+		return fmt.Sprintf("Record merchant '%s' as '%s' from now on", alias, merchant)
+	}
+	return tool
+}
@@ -0,0 +1,186 @@
+package hitl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"finagent/internal/agent/tools"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// SessionRef identifies the session that owns the pending actions.
+type SessionRef struct {
+	AppName   string
+	UserID    string
+	SessionID string
+}
+
+// Decision is the user's answer to a pending action.
+type Decision string
+
+const (
+	Approve Decision = "ok"
+	Cancel  Decision = "no"
+	// Edit discards the action and asks the model to collect corrections
+	// and propose it again.
+	Edit Decision = "edit"
+)
+
+// ParseDecision maps a callback value ("ok", "no", "edit") to a Decision.
+func ParseDecision(s string) (Decision, bool) {
+	switch d := Decision(s); d {
+	case Approve, Cancel, Edit:
+		return d, true
+	}
+	return "", false
+}
+
+// Resolution is the outcome of a user decision.
+type Resolution struct {
+	Action   PendingAction
+	Decision Decision
+	Approved bool
+	Message  string // tool result message on success
+	Err      error  // execution error, the action is dropped either way
+}
+
+// List returns the pending actions of a session.
+func List(ctx context.Context, svc session.Service, ref SessionRef) ([]PendingAction, error) {
+	resp, err := svc.Get(ctx, &session.GetRequest{
+		AppName:   ref.AppName,
+		UserID:    ref.UserID,
+		SessionID: ref.SessionID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return Pending(resp.Session.State()), nil
+}
+
+// Resolve executes (approve) or discards (reject) a pending action.
+func Resolve(ctx context.Context, svc session.Service, ref SessionRef, actionID string, approve bool) (*Resolution, error) {
+	decision := Cancel
+	if approve {
+		decision = Approve
+	}
+	return Decide(ctx, svc, ref, actionID, decision)
+}
+
+// Decide applies a decision to a pending action, removes it from session
+// state and records the decision in the session history so the model sees
+// it on the next turn. The action is removed before it runs, so a failure
+// to save the session can never leave an executed action pending: at
+// worst an approved action is lost, never applied twice.
+func Decide(ctx context.Context, svc session.Service, ref SessionRef, actionID string, decision Decision) (*Resolution, error) {
+	resp, err := svc.Get(ctx, &session.GetRequest{
+		AppName:   ref.AppName,
+		UserID:    ref.UserID,
+		SessionID: ref.SessionID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	var res *Resolution
+	for _, action := range Pending(resp.Session.State()) {
+		if action.ID == actionID {
+			res = &Resolution{Action: action, Decision: decision, Approved: decision == Approve}
+			break
+		}
+	}
+	if res == nil {
+		return nil, fmt.Errorf("action %s not found or already resolved", actionID)
+	}
+
+	resolved := session.NewEvent("hitl_" + res.Action.ID)
+	resolved.Author = "user"
+	resolved.Actions.StateDelta[StateKey(res.Action.ID)] = ""
+	if err := svc.AppendEvent(ctx, resp.Session, resolved); err != nil {
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	if res.Approved {
+		res.Message, res.Err = Execute(tools.WithUserID(ctx, ref.UserID), res.Action)
+	}
+
+	note := fmt.Sprintf("[confirmation] User cancelled action %s: %s", res.Action.ID, res.Action.Summary)
+	switch {
+	case decision == Edit:
+		note = fmt.Sprintf("[confirmation] User wants to edit action %s before saving: %s. "+
+			"Nothing was saved. Ask what to change, then call the tool again with the corrected data.", res.Action.ID, res.Action.Summary)
+	case res.Approved && res.Err != nil:
+		note = fmt.Sprintf("[confirmation] User approved action %s but it failed: %v", res.Action.ID, res.Err)
+	case res.Approved:
+		note = fmt.Sprintf("[confirmation] User approved action %s: %s", res.Action.ID, res.Message)
+	}
+
+	ev := session.NewEvent("hitl_" + res.Action.ID)
+	ev.Author = "user"
+	ev.Content = genai.NewContentFromText(note, genai.RoleUser)
+
+	if err := svc.AppendEvent(ctx, resp.Session, ev); err != nil {
+		return res, fmt.Errorf("failed to update session: %w", err)
+	}
+	return res, nil
+}
+
+// Execute runs a pending action against the sheet tools. Appended rows
+// are attributed to whoever sent the message, not who confirmed it.
+func Execute(ctx context.Context, action PendingAction) (string, error) {
+	if action.AddedBy != "" {
+		ctx = tools.WithAuthor(ctx, action.AddedBy)
+	}
+
+	raw, err := json.Marshal(action.Args)
+	if err != nil {
+		return "", fmt.Errorf("invalid action args: %w", err)
+	}
+
+	switch action.Tool {
+	case "append_to_sheet":
+		var args tools.AppendSheetArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return "", fmt.Errorf("invalid append args: %w", err)
+		}
+		if err := tools.AppendToSheet(ctx, args.SheetName, args.Values); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Successfully appended %d rows to %s", len(args.Values), args.SheetName), nil
+
+	case "write_to_sheet":
+		var args tools.WriteSheetArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return "", fmt.Errorf("invalid write args: %w", err)
+		}
+		if err := tools.WriteToSheet(ctx, args.SheetName, args.RangeNotation, args.Values); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Successfully wrote to %s!%s", args.SheetName, args.RangeNotation), nil
+
+	case "create_new_sheet":
+		var args tools.CreateSheetArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return "", fmt.Errorf("invalid create args: %w", err)
+		}
+		name, err := tools.CreateNewSheet(ctx, args.SheetTitle)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Successfully created sheet '%s'", name), nil
+
+	case "add_merchant_alias":
+		var args tools.AddMerchantAliasArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return "", fmt.Errorf("invalid alias args: %w", err)
+		}
+		if err := tools.AddMerchantAlias(args.Alias, args.Merchant); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("'%s' will now be recorded as '%s'", args.Alias, args.Merchant), nil
+	}
+
+	return "", fmt.Errorf("unsupported action tool %q", action.Tool)
+}
@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// SummaryStateKey holds the summary of turns compacted out of the session
+// history. The instruction includes it so the model keeps that context.
+const SummaryStateKey = "conversation_summary"
+
+const summaryPrompt = `You compact the history of a chat between a user and a financial
+transaction tracker assistant. Write at most 10 short bullet points, in the
+language of the conversation, keeping only what later turns may need:
+- transactions saved (merchant, date, total, sheet name)
+- receipts or changes still waiting for the user's confirmation
+- corrections and preferences the user stated (sheet, categories, names)
+- open questions
+Drop greetings, tool mechanics and anything already resolved.`
+
+// Summarizer condenses conversation transcripts with the model.
+type Summarizer struct {
+	llm model.LLM
+}
+
+func NewSummarizer(llm model.LLM) *Summarizer {
+	return &Summarizer{llm: llm}
+}
+
+// Summarize merges the previous summary (may be empty) with the transcript
+// of the turns being dropped.
+func (s *Summarizer) Summarize(ctx context.Context, previous, transcript string) (string, error) {
+	input := "Conversation:\n" + transcript
+	if previous != "" {
+		input = "Summary so far:\n" + previous + "\n\n" + input
+	}
+
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{genai.NewContentFromText(input, genai.RoleUser)},
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: genai.NewContentFromText(summaryPrompt, genai.RoleUser),
+		},
+	}
+
+	var sb strings.Builder
+	for resp, err := range s.llm.GenerateContent(ctx, req, false) {
+		if err != nil {
+			return "", fmt.Errorf("failed to summarize: %w", err)
+		}
+		if resp.Content == nil {
+			continue
+		}
+		for _, part := range resp.Content.Parts {
+			if part.Text != "" && !part.Thought {
+				sb.WriteString(part.Text)
+			}
+		}
+	}
+
+	summary := strings.TrimSpace(sb.String())
+	if summary == "" {
+		return "", fmt.Errorf("failed to summarize: empty response")
+	}
+	return summary, nil
+}
+
+// Transcript renders events as plain text for summarizing: messages, tool
+// calls and tool outcomes, one per line. Images and audio are marked only.
+func Transcript(events []*session.Event) string {
+	var lines []string
+	for _, event := range events {
+		if event.Content == nil {
+			continue
+		}
+
+		speaker := "Assistant"
+		if event.Author == "user" {
+			speaker = "User"
+		}
+
+		for _, part := range event.Content.Parts {
+			switch {
+			case part.Thought:
+				continue
+			case part.Text != "":
+				lines = append(lines, fmt.Sprintf("%s: %s", speaker, strings.TrimSpace(part.Text)))
+			case part.InlineData != nil || part.FileData != nil:
+				lines = append(lines, fmt.Sprintf("%s: [attachment]", speaker))
+			case part.FunctionCall != nil:
+				args, _ := json.Marshal(part.FunctionCall.Args)
+				lines = append(lines, fmt.Sprintf("Tool call: %s %s", part.FunctionCall.Name, truncate(string(args), 300)))
+			case part.FunctionResponse != nil:
+				resp := part.FunctionResponse.Response
+				outcome := fmt.Sprintf("%v", resp["status"])
+				if msg, ok := resp["message"].(string); ok && msg != "" {
+					outcome += ": " + msg
+				}
+				if errMsg, ok := resp["error"].(string); ok && errMsg != "" {
+					outcome += ": " + errMsg
+				}
+				lines = append(lines, fmt.Sprintf("Tool result: %s %s", part.FunctionResponse.Name, truncate(outcome, 300)))
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
@@ -8,8 +8,15 @@ import (
 	"google.golang.org/adk/tool/functiontool"
 )
 
+// userContext routes the call to the spreadsheet of the invoking user. It
+// derives from the run's context, so cancelling the run aborts Sheets
+// requests in flight and the author set with WithAuthor is kept.
+func userContext(ctx tool.Context) context.Context {
+	return WithUserID(ctx, ctx.UserID())
+}
+
 func readFromSheet(ctx tool.Context, args ReadSheetArgs) (ReadSheetResult, error) {
-	data, err := ReadFromSheet(context.Background(), args.SheetName, args.RangeNotation)
+	data, err := ReadFromSheet(userContext(ctx), args.SheetName, args.RangeNotation)
 	if err != nil {
 		return ReadSheetResult{Status: "error", Error: err.Error()}, nil
 	}
@@ -17,7 +24,7 @@ func readFromSheet(ctx tool.Context, args ReadSheetArgs) (ReadSheetResult, error
 }
 
 func writeToSheet(ctx tool.Context, args WriteSheetArgs) (WriteSheetResult, error) {
-	err := WriteToSheet(context.Background(), args.SheetName, args.RangeNotation, args.Values)
+	err := WriteToSheet(userContext(ctx), args.SheetName, args.RangeNotation, args.Values)
 	if err != nil {
 		return WriteSheetResult{Status: "error", Error: err.Error()}, nil
 	}
@@ -26,7 +33,7 @@ func writeToSheet(ctx tool.Context, args WriteSheetArgs) (WriteSheetResult, erro
 }
 
 func appendToSheet(ctx tool.Context, args AppendSheetArgs) (AppendSheetResult, error) {
-	err := AppendToSheet(context.Background(), args.SheetName, args.Values)
+	err := AppendToSheet(userContext(ctx), args.SheetName, args.Values)
 	if err != nil {
 		return AppendSheetResult{Status: "error", Error: err.Error()}, nil
 	}
@@ -35,16 +42,16 @@ func appendToSheet(ctx tool.Context, args AppendSheetArgs) (AppendSheetResult, e
 }
 
 func createNewSheet(ctx tool.Context, args CreateSheetArgs) (CreateSheetResult, error) {
-	err := CreateNewSheet(context.Background(), args.SheetTitle)
+	name, err := CreateNewSheet(userContext(ctx), args.SheetTitle)
 	if err != nil {
 		return CreateSheetResult{Status: "error", Error: err.Error()}, nil
 	}
-	msg := fmt.Sprintf("Successfully created sheet 'Transaction_%s_{date}'", args.SheetTitle)
+	msg := fmt.Sprintf("Successfully created sheet '%s'", name)
 	return CreateSheetResult{Status: "success", Message: msg}, nil
 }
 
 func listSheets(ctx tool.Context, args struct{}) (ListSheetsResult, error) {
-	sheets, err := ListSheetsWithInfo(context.Background())
+	sheets, err := ListSheetsWithInfo(userContext(ctx))
 	if err != nil {
 		return ListSheetsResult{Status: "error", Error: err.Error()}, nil
 	}
@@ -55,6 +62,14 @@ func listSheets(ctx tool.Context, args struct{}) (ListSheetsResult, error) {
 	}, nil
 }
 
+func addMerchantAlias(ctx tool.Context, args AddMerchantAliasArgs) (AddMerchantAliasResult, error) {
+	if err := AddMerchantAlias(args.Alias, args.Merchant); err != nil {
+		return AddMerchantAliasResult{Status: "error", Error: err.Error()}, nil
+	}
+	msg := fmt.Sprintf("'%s' will now be recorded as '%s'", args.Alias, args.Merchant)
+	return AddMerchantAliasResult{Status: "success", Message: msg}, nil
+}
+
 func NewAdkToolSheets() ([]tool.Tool, error) {
 	readTool, err := functiontool.New(
 		functiontool.Config{
@@ -76,7 +91,8 @@ Returns: 2D array of cell values`,
 			Description: `Write/overwrite data to a specific Google Sheet range.
 Usage: Update existing cells or modify specific ranges.
 Args: sheetName, rangeNotation, values (2D array)
-WARNING: This overwrites existing data. Use append_to_sheet for adding new rows.`,
+WARNING: This overwrites existing data. Use append_to_sheet for adding new rows.
+Requires user confirmation: returns status "pending_confirmation" until the user approves.`,
 		},
 		writeToSheet,
 	)
@@ -97,7 +113,10 @@ Args:
 IMPORTANT:
   - Leave 'no' empty ("") for auto-increment
   - Required fields: item_name, amount, merchant, receipt_id
-  - Backend auto-fills: no, qty (default=1), receipt_date (if empty), input_source
+  - Backend auto-fills: no, qty (default=1), receipt_date (if empty), input_source,
+    and a 12th column added_by (who sent the message) - don't add it yourself
+  - Requires user confirmation: returns status "pending_confirmation" with an actionId,
+    rows are written only after the user approves
   
 Example:
   values: [
@@ -121,7 +140,7 @@ Auto-naming format: Transaction_{sheetTitle}_{YYYYMMDD}
 Example: "Groceries" → "Transaction_Groceries_20251217"
 
 The sheet will be created with:
-  - Standard 11-column header (no, item_name, qty, unit, unit_price, amount, category, merchant, receipt_date, input_source, receipt_id)
+  - Standard header (no, item_name, qty, unit, unit_price, amount, category, merchant, receipt_date, input_source, receipt_id, added_by)
   - Frozen header row
   - Formatted header (bold, light green background)
   
@@ -160,11 +179,29 @@ Use this BEFORE creating new sheets to find available empty sheets.`,
 		return nil, err
 	}
 
+	merchantAliasTool, err := functiontool.New(
+		functiontool.Config{
+			Name: "add_merchant_alias",
+			Description: `Map a raw merchant name to its canonical merchant (column H).
+Usage: When the user says two names are the same store, e.g. "Indomaret Point is just Indomaret".
+Args:
+  - alias: Raw name as printed on receipts (e.g., "INDOMARET PT INDOMARCO")
+  - merchant: Canonical merchant name to record instead (e.g., "Indomaret")
+
+The backend already maps known aliases and close spellings before every append.`,
+		},
+		addMerchantAlias,
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	return []tool.Tool{
 		listSheetsTool, // List first (untuk discovery)
 		readTool,
 		appendTool, // Most used for transactions
 		createSheetTool,
 		writeTool, // Least used (careful operation)
+		merchantAliasTool,
 	}, nil
 }
@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Categories is the fixed taxonomy for column G. Anything else is rejected.
+var Categories = []string{
+	"Food",
+	"Groceries",
+	"Transport",
+	"Shopping",
+	"Bills",
+	"Health",
+	"Entertainment",
+	"Education",
+	"Other",
+}
+
+// DefaultCategory is used when no rule matches and the model gave none.
+const DefaultCategory = "Other"
+
+// CategoryRule maps merchant and item patterns to a category. Patterns are
+// case-insensitive regular expressions; a plain word matches as substring.
+type CategoryRule struct {
+	Category  string   `json:"category"`
+	Merchants []string `json:"merchants,omitempty"`
+	Items     []string `json:"items,omitempty"`
+
+	merchantRes []*regexp.Regexp
+	itemRes     []*regexp.Regexp
+}
+
+type categoryRulesFile struct {
+	Rules []CategoryRule `json:"rules"`
+}
+
+var categoryRules []CategoryRule
+
+// InitCategoryRules loads the rules file from CATEGORY_RULES_PATH
+// (default: config/category_rules.json). A missing file means no rules.
+func InitCategoryRules() error {
+	path := os.Getenv("CATEGORY_RULES_PATH")
+	if path == "" {
+		path = "config/category_rules.json"
+	}
+
+	rules, err := LoadCategoryRules(path)
+	if os.IsNotExist(err) {
+		categoryRules = nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	categoryRules = rules
+	return nil
+}
+
+func LoadCategoryRules(path string) ([]CategoryRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file categoryRulesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	for i := range file.Rules {
+		rule := &file.Rules[i]
+
+		category, ok := canonicalCategory(rule.Category)
+		if !ok {
+			return nil, fmt.Errorf("%s: rule %d: unknown category '%s'", path, i+1, rule.Category)
+		}
+		rule.Category = category
+
+		for _, p := range rule.Merchants {
+			re, err := regexp.Compile("(?i)" + p)
+			if err != nil {
+				return nil, fmt.Errorf("%s: rule %d: invalid merchant pattern %q: %w", path, i+1, p, err)
+			}
+			rule.merchantRes = append(rule.merchantRes, re)
+		}
+		for _, p := range rule.Items {
+			re, err := regexp.Compile("(?i)" + p)
+			if err != nil {
+				return nil, fmt.Errorf("%s: rule %d: invalid item pattern %q: %w", path, i+1, p, err)
+			}
+			rule.itemRes = append(rule.itemRes, re)
+		}
+	}
+
+	return file.Rules, nil
+}
+
+// SetCategoryRules replaces the active rules, e.g. for replays running
+// outside the repository root.
+func SetCategoryRules(rules []CategoryRule) {
+	categoryRules = rules
+}
+
+// resolveCategory picks column G: the first rule matching the merchant
+// wins, then the first rule matching the item, otherwise the model's guess
+// if it is in the taxonomy.
+func resolveCategory(merchant, itemName, guess interface{}) (string, error) {
+	m := fmt.Sprintf("%v", merchant)
+	item := fmt.Sprintf("%v", itemName)
+
+	for _, rule := range categoryRules {
+		if matchAny(rule.merchantRes, m) {
+			return rule.Category, nil
+		}
+	}
+	for _, rule := range categoryRules {
+		if matchAny(rule.itemRes, item) {
+			return rule.Category, nil
+		}
+	}
+
+	if isEmpty(guess) {
+		return DefaultCategory, nil
+	}
+
+	category, ok := canonicalCategory(fmt.Sprintf("%v", guess))
+	if !ok {
+		return "", fmt.Errorf("unknown category '%v' (allowed: %s)", guess, strings.Join(Categories, ", "))
+	}
+	return category, nil
+}
+
+func canonicalCategory(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	for _, c := range Categories {
+		if strings.EqualFold(c, name) {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+func matchAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func loadTestRules(t *testing.T, content string) []CategoryRule {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "category_rules.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadCategoryRules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rules
+}
+
+func TestResolveCategory(t *testing.T) {
+	// Food is listed first, so an item keyword of an earlier rule must
+	// still lose to a merchant pattern of a later one
+	SetCategoryRules(loadTestRules(t, `{"rules": [
+		{"category": "food", "merchants": ["warung"], "items": ["kopi", "nasi"]},
+		{"category": "Groceries", "merchants": ["indomaret", "\\bhero\\b"], "items": ["beras"]},
+		{"category": "Bills", "items": ["token listrik"]}
+	]}`))
+	t.Cleanup(func() { SetCategoryRules(nil) })
+
+	tests := []struct {
+		name                  string
+		merchant, item, guess interface{}
+		want                  string
+	}{
+		{"merchant rule beats item keyword", "Indomaret Point", "Kopi susu", "Food", "Groceries"},
+		{"merchant rule beats model guess", "Warung Bu Sri", "Es jeruk", "Shopping", "Food"},
+		{"item keyword beats model guess", "Toko Budi", "Token Listrik 100rb", "Shopping", "Bills"},
+		{"word boundary pattern", "Superhero Store", "Kaos", "Shopping", "Shopping"},
+		{"model guess when no rule matches", "Toko Budi", "Kaos", "shopping", "Shopping"},
+		{"default without a guess", "Toko Budi", "Kaos", "", DefaultCategory},
+		{"default with a nil guess", "Toko Budi", "Kaos", nil, DefaultCategory},
+		{"rule category is canonical", "Warung", "", nil, "Food"},
+	}
+	for _, tt := range tests {
+		got, err := resolveCategory(tt.merchant, tt.item, tt.guess)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: category = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResolveCategoryRejectsUnknown(t *testing.T) {
+	SetCategoryRules([]CategoryRule{})
+	t.Cleanup(func() { SetCategoryRules(nil) })
+
+	for _, guess := range []string{"Snacks", "Food & Drink", "Makanan"} {
+		_, err := resolveCategory("Toko Budi", "Keripik", guess)
+		if err == nil {
+			t.Errorf("guess %q was accepted", guess)
+			continue
+		}
+		if !strings.Contains(err.Error(), "allowed: "+strings.Join(Categories, ", ")) {
+			t.Errorf("guess %q: error %q does not list the taxonomy", guess, err)
+		}
+	}
+}
+
+func TestLoadCategoryRulesRejectsUnknownCategory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "category_rules.json")
+	content := `{"rules": [{"category": "Snacks", "merchants": ["chitato"]}]}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCategoryRules(path); err == nil || !strings.Contains(err.Error(), "unknown category 'Snacks'") {
+		t.Errorf("err = %v, want unknown category", err)
+	}
+}
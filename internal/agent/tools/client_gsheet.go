@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -141,6 +142,33 @@ func (s *SheetClient) GetSheetInfo(ctx context.Context, sheetName string) (*Shee
 	return nil, fmt.Errorf("sheet '%s' not found", sheetName)
 }
 
+func (s *SheetClient) DeleteRows(ctx context.Context, sheetName string, from, to int) error {
+	info, err := s.GetSheetInfo(ctx, sheetName)
+	if err != nil {
+		return err
+	}
+
+	deleteReq := &sheets.Request{
+		DeleteDimension: &sheets.DeleteDimensionRequest{
+			Range: &sheets.DimensionRange{
+				SheetId:    info.SheetID,
+				Dimension:  "ROWS",
+				StartIndex: int64(from - 1),
+				EndIndex:   int64(to),
+			},
+		},
+	}
+
+	batchReq := &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{deleteReq},
+	}
+
+	if _, err := s.service.Spreadsheets.BatchUpdate(s.spreadsheetID, batchReq).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("delete rows failed: %w", err)
+	}
+	return nil
+}
+
 // === Formatting ===
 
 func (s *SheetClient) FormatHeader(ctx context.Context, sheetID int64, colCount int) error {
@@ -200,13 +228,72 @@ func (s *SheetClient) GetLastRowNumber(ctx context.Context, sheetName string) (i
 
 // === Global singleton ===
 
-var globalClient *SheetClient
+// SheetStore is the storage backend behind the tools. *SheetClient talks to
+// the Google Sheets API; MemorySheetStore keeps everything in memory for
+// replays and offline runs.
+type SheetStore interface {
+	Read(ctx context.Context, sheetName, rangeNotation string) ([][]interface{}, error)
+	Write(ctx context.Context, sheetName, rangeNotation string, values [][]interface{}) error
+	Append(ctx context.Context, sheetName string, values [][]interface{}) error
+	Create(ctx context.Context, title string) (int64, error)
+	ListSheets(ctx context.Context) ([]SheetInfo, error)
+	FormatHeader(ctx context.Context, sheetID int64, colCount int) error
+	GetLastRowNumber(ctx context.Context, sheetName string) (int, error)
+	// DeleteRows removes the 1-based rows from..to (inclusive), shifting
+	// the rows below up.
+	DeleteRows(ctx context.Context, sheetName string, from, to int) error
+}
+
+var globalClient SheetStore
 
+// InitSheetClient sets up the default spreadsheet (SPREADSHEET_ID, optional
+// when every user connects their own) and the per-user registry at
+// USER_SHEETS_PATH (default: data/user_sheets.json).
 func InitSheetClient(ctx context.Context) error {
 	credPath := os.Getenv("GOOGLE_SA_PATH")
 	spreadsheetID := os.Getenv("SPREADSHEET_ID")
 
-	var err error
-	globalClient, err = NewSheetClient(ctx, credPath, spreadsheetID)
-	return err
+	globalClient = nil
+	if spreadsheetID != "" {
+		client, err := NewSheetClient(ctx, credPath, spreadsheetID)
+		if err != nil {
+			return err
+		}
+		globalClient = client
+	}
+
+	path := os.Getenv("USER_SHEETS_PATH")
+	if path == "" {
+		path = "data/user_sheets.json"
+	}
+
+	reg, err := LoadSheetRegistry(path, func(ctx context.Context, id string) (SheetStore, error) {
+		return NewSheetClient(ctx, credPath, id)
+	})
+	if err != nil {
+		return err
+	}
+	reg.defaultID = spreadsheetID
+	registry = reg
+	return nil
+}
+
+// ServiceAccountEmail returns the client_email of GOOGLE_SA_PATH, the
+// address users share their spreadsheet with.
+func ServiceAccountEmail() string {
+	data, err := os.ReadFile(os.Getenv("GOOGLE_SA_PATH"))
+	if err != nil {
+		return ""
+	}
+	var cred struct {
+		ClientEmail string `json:"client_email"`
+	}
+	json.Unmarshal(data, &cred)
+	return cred.ClientEmail
+}
+
+// SetSheetStore replaces the backend used by the tools for every user.
+func SetSheetStore(store SheetStore) {
+	globalClient = store
+	registry = nil
 }
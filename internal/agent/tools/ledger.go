@@ -0,0 +1,391 @@
+package tools
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Transaction is one data row of a transaction sheet.
+type Transaction struct {
+	Sheet     string
+	Row       int // 1-based row in the sheet
+	ItemName  string
+	Qty       string
+	Amount    float64
+	Category  string
+	Merchant  string
+	Date      time.Time // receipt_date; zero if it can't be parsed
+	ReceiptID string
+	AddedBy   string
+	Values    []interface{} // the raw standard columns
+}
+
+// Total is an amount grouped under a name (category or merchant).
+type Total struct {
+	Name   string
+	Amount float64
+}
+
+// Summary aggregates the transactions dated within [From, To).
+type Summary struct {
+	From, To   time.Time
+	Count      int
+	Receipts   int
+	Total      float64
+	Categories []Total // largest first
+	Merchants  []Total // largest first
+}
+
+var (
+	sheetDatePattern = regexp.MustCompile(`_(\d{8})$`)
+	thousandsPattern = regexp.MustCompile(`^\d{1,3}(\.\d{3})+$`)
+)
+
+// LastAppend is the most recent AppendToSheet of a user, recorded at write
+// time so an undo removes exactly those rows.
+type LastAppend struct {
+	Seq       int64 // changes with every append
+	Sheet     string
+	ReceiptID string
+	Rows      int
+}
+
+// ErrNothingToUndo is returned by LastReceipt when the user has no append
+// since the process started.
+var ErrNothingToUndo = errors.New("no receipt to undo")
+
+var (
+	lastAppendMu sync.Mutex
+	// lastAppendSeq starts at the clock so an undo button from before a
+	// restart never matches a new append.
+	lastAppendSeq = time.Now().UnixNano()
+	lastAppends   = make(map[string]LastAppend)
+)
+
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"1/2/2006 15:04:05",
+	"1/2/2006",
+}
+
+// Transactions reads every transaction sheet of the user's spreadsheet.
+// Sheets are ordered by the date in their name, rows as stored, so the
+// last transaction is the most recently added one.
+func Transactions(ctx context.Context) ([]Transaction, error) {
+	store, err := storeFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sheets, err := store.ListSheets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(sheets, func(i, j int) bool {
+		return sheetDate(sheets[i].Title) < sheetDate(sheets[j].Title)
+	})
+
+	var txs []Transaction
+	for _, sheet := range sheets {
+		if sheet.IsEmpty {
+			continue
+		}
+
+		sheetTxs, err := sheetTransactions(ctx, store, sheet.Title)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, sheetTxs...)
+	}
+	return txs, nil
+}
+
+// sheetTransactions reads the transactions of one sheet; none if it has no
+// header row.
+func sheetTransactions(ctx context.Context, store SheetStore, sheetName string) ([]Transaction, error) {
+	rows, err := store.Read(ctx, sheetName, fmt.Sprintf("A1:%s", columnLetter(len(DefaultHeaders))))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", sheetName, err)
+	}
+	if len(rows) == 0 || !isHeaderRow(rows[0]) {
+		return nil, nil
+	}
+
+	var txs []Transaction
+	for i, row := range rows[1:] {
+		if tx, ok := parseTransaction(sheetName, i+2, row); ok {
+			txs = append(txs, tx)
+		}
+	}
+	return txs, nil
+}
+
+// LastTransactions returns up to n of the most recently added transactions,
+// oldest first.
+func LastTransactions(ctx context.Context, n int) ([]Transaction, error) {
+	txs, err := Transactions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(txs) > n {
+		txs = txs[len(txs)-n:]
+	}
+	return txs, nil
+}
+
+// Summarize totals the transactions dated within [from, to).
+func Summarize(txs []Transaction, from, to time.Time) Summary {
+	summary := Summary{From: from, To: to}
+	categories := make(map[string]float64)
+	merchants := make(map[string]float64)
+	receipts := make(map[string]bool)
+
+	for _, tx := range txs {
+		if tx.Date.IsZero() || tx.Date.Before(from) || !tx.Date.Before(to) {
+			continue
+		}
+		summary.Count++
+		summary.Total += tx.Amount
+		categories[tx.Category] += tx.Amount
+		merchants[tx.Merchant] += tx.Amount
+		receipts[tx.ReceiptID] = true
+	}
+
+	summary.Receipts = len(receipts)
+	summary.Categories = sortTotals(categories)
+	summary.Merchants = sortTotals(merchants)
+	return summary
+}
+
+// recordAppend remembers rows appended to sheetName as the user's last
+// receipt.
+func recordAppend(ctx context.Context, sheetName string, rows [][]interface{}) {
+	last := rows[len(rows)-1]
+
+	lastAppendMu.Lock()
+	defer lastAppendMu.Unlock()
+	lastAppendSeq++
+	lastAppends[userIDFrom(ctx)] = LastAppend{
+		Seq:       lastAppendSeq,
+		Sheet:     sheetName,
+		ReceiptID: strings.TrimSpace(fmt.Sprintf("%v", last[ColReceiptID])),
+		Rows:      len(rows),
+	}
+}
+
+// LastReceipt returns the user's last append and the rows it still has in
+// the sheet, so they can be shown before UndoLastReceipt deletes them.
+// Only appends made since the process started are known.
+func LastReceipt(ctx context.Context) (LastAppend, []Transaction, error) {
+	lastAppendMu.Lock()
+	last, ok := lastAppends[userIDFrom(ctx)]
+	lastAppendMu.Unlock()
+	if !ok {
+		return LastAppend{}, nil, ErrNothingToUndo
+	}
+
+	store, err := storeFor(ctx)
+	if err != nil {
+		return LastAppend{}, nil, err
+	}
+	txs, err := sheetTransactions(ctx, store, last.Sheet)
+	if err != nil {
+		return LastAppend{}, nil, err
+	}
+
+	// The newest run of rows with the receipt_id, at most as many as were
+	// appended; earlier appends of the same receipt are kept
+	end := len(txs) - 1
+	for end >= 0 && txs[end].ReceiptID != last.ReceiptID {
+		end--
+	}
+	if end < 0 {
+		return LastAppend{}, nil, fmt.Errorf("receipt %s is no longer in %s", last.ReceiptID, last.Sheet)
+	}
+	start := end
+	for start > 0 && end-start+1 < last.Rows {
+		prev := txs[start-1]
+		if prev.ReceiptID != last.ReceiptID || prev.Row != txs[start].Row-1 {
+			break
+		}
+		start--
+	}
+	return last, txs[start : end+1], nil
+}
+
+// UndoLastReceipt deletes the rows of the user's last append and returns
+// them. seq is the LastAppend.Seq that was shown for confirmation; it
+// fails if another append happened since.
+func UndoLastReceipt(ctx context.Context, seq int64) ([]Transaction, error) {
+	last, removed, err := LastReceipt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if last.Seq != seq {
+		return nil, fmt.Errorf("another receipt was added since, undo again to see it")
+	}
+
+	store, err := storeFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.DeleteRows(ctx, last.Sheet, removed[0].Row, removed[len(removed)-1].Row); err != nil {
+		return nil, fmt.Errorf("failed to delete rows: %w", err)
+	}
+
+	lastAppendMu.Lock()
+	if lastAppends[userIDFrom(ctx)].Seq == seq {
+		delete(lastAppends, userIDFrom(ctx))
+	}
+	lastAppendMu.Unlock()
+	return removed, nil
+}
+
+// WriteCSV writes transactions as CSV with the sheet name and the standard
+// columns.
+func WriteCSV(w io.Writer, txs []Transaction) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"sheet"}, DefaultHeaders...)); err != nil {
+		return err
+	}
+
+	for _, tx := range txs {
+		record := make([]string, 0, len(DefaultHeaders)+1)
+		record = append(record, tx.Sheet)
+		for i := range DefaultHeaders {
+			cell := ""
+			if i < len(tx.Values) && tx.Values[i] != nil {
+				cell = fmt.Sprintf("%v", tx.Values[i])
+			}
+			record = append(record, cell)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ParseAmount reads an amount cell: "25000", "25,000.50", "Rp 25.000".
+func ParseAmount(val interface{}) (float64, bool) {
+	s := strings.TrimSpace(fmt.Sprintf("%v", val))
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "Rp"), "IDR")
+	s = strings.TrimSpace(s)
+
+	if thousandsPattern.MatchString(s) {
+		s = strings.ReplaceAll(s, ".", "")
+	}
+	s = strings.ReplaceAll(s, ",", "")
+
+	amount, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return amount, true
+}
+
+// FormatRupiah formats an amount as "Rp188.500".
+func FormatRupiah(amount float64) string {
+	n := int64(math.Round(amount))
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+
+	digits := strconv.FormatInt(n, 10)
+	var sb strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteRune(d)
+	}
+	return sign + "Rp" + sb.String()
+}
+
+// ParseDate reads a receipt_date cell in the formats the tools write and
+// the Sheets API returns.
+func ParseDate(val interface{}) (time.Time, bool) {
+	s := strings.TrimSpace(fmt.Sprintf("%v", val))
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func parseTransaction(sheet string, rowNum int, row []interface{}) (Transaction, bool) {
+	values := make([]interface{}, len(DefaultHeaders))
+	copy(values, row)
+	if isEmpty(values[ColItemName]) && isEmpty(values[ColAmount]) {
+		return Transaction{}, false
+	}
+
+	cell := func(col int) string {
+		if values[col] == nil {
+			return ""
+		}
+		return strings.TrimSpace(fmt.Sprintf("%v", values[col]))
+	}
+
+	amount, _ := ParseAmount(values[ColAmount])
+	date, _ := ParseDate(values[ColReceiptDate])
+	return Transaction{
+		Sheet:     sheet,
+		Row:       rowNum,
+		ItemName:  cell(ColItemName),
+		Qty:       cell(ColQty),
+		Amount:    amount,
+		Category:  cell(ColCategory),
+		Merchant:  cell(ColMerchant),
+		Date:      date,
+		ReceiptID: cell(ColReceiptID),
+		AddedBy:   cell(ColAddedBy),
+		Values:    values,
+	}, true
+}
+
+func isHeaderRow(row []interface{}) bool {
+	return len(row) > ColItemName &&
+		strings.EqualFold(fmt.Sprintf("%v", row[ColNo]), DefaultHeaders[ColNo]) &&
+		strings.EqualFold(fmt.Sprintf("%v", row[ColItemName]), DefaultHeaders[ColItemName])
+}
+
+// sheetDate returns the YYYYMMDD suffix of a sheet name, "" if none.
+func sheetDate(title string) string {
+	if m := sheetDatePattern.FindStringSubmatch(title); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+func sortTotals(totals map[string]float64) []Total {
+	out := make([]Total, 0, len(totals))
+	for name, amount := range totals {
+		out = append(out, Total{Name: name, Amount: amount})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Amount != out[j].Amount {
+			return out[i].Amount > out[j].Amount
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
@@ -0,0 +1,107 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in     interface{}
+		want   float64
+		wantOK bool
+	}{
+		{"25000", 25000, true},
+		{25000, 25000, true},
+		{18500.5, 18500.5, true},
+		{"25,000.50", 25000.5, true},
+		{"Rp 25.000", 25000, true},
+		{"Rp1.250.000", 1250000, true},
+		{"IDR 7,500", 7500, true},
+		{"12.5", 12.5, true},
+		{"", 0, false},
+		{"gratis", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseAmount(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseAmount(%v) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestUndoLastReceipt(t *testing.T) {
+	store := NewMemorySheetStore()
+	header := [][]interface{}{toInterfaceSlice(DefaultHeaders)}
+	store.Seed("Transaction_Tracker_20260301", header)
+	store.Seed("Transaction_Tracker_20260315", header)
+	SetSheetStore(store)
+	SetCategoryRules([]CategoryRule{})
+	SetMerchantDictionary(&MerchantDictionary{})
+	defer SetSheetStore(nil)
+
+	ctx := WithUserID(context.Background(), "tg_1")
+	row := func(item, receiptID string) []interface{} {
+		return []interface{}{"", item, 1, 10000, 10000, 10000, "Food", "Warung", "2026-03-01", "manual", receiptID}
+	}
+	appends := []struct {
+		sheet string
+		rows  [][]interface{}
+	}{
+		{"Transaction_Tracker_20260315", [][]interface{}{row("Nasi", "R1"), row("Teh", "R1")}},
+		// Backdated receipt: the last append is not in the newest sheet
+		{"Transaction_Tracker_20260301", [][]interface{}{row("Kopi", "R2"), row("Roti", "R2")}},
+	}
+	for _, a := range appends {
+		if err := AppendToSheet(ctx, a.sheet, a.rows); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	last, rows, err := LastReceipt(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.Sheet != "Transaction_Tracker_20260301" || last.ReceiptID != "R2" || len(rows) != 2 {
+		t.Fatalf("LastReceipt() = %+v, %d rows", last, len(rows))
+	}
+	if _, _, err := LastReceipt(WithUserID(context.Background(), "tg_2")); !errors.Is(err, ErrNothingToUndo) {
+		t.Error("another chat can undo this receipt")
+	}
+	if _, err := UndoLastReceipt(ctx, last.Seq-1); err == nil {
+		t.Error("undo with a stale seq succeeded")
+	}
+
+	removed, err := UndoLastReceipt(ctx, last.Seq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 2 || removed[0].ItemName != "Kopi" {
+		t.Errorf("removed %+v", removed)
+	}
+	if got := len(store.Rows("Transaction_Tracker_20260301")); got != 1 {
+		t.Errorf("older sheet has %d rows, want the header only", got)
+	}
+	if got := len(store.Rows("Transaction_Tracker_20260315")); got != 3 {
+		t.Errorf("newest sheet has %d rows, want it untouched", got)
+	}
+	if _, err := UndoLastReceipt(ctx, last.Seq); err == nil {
+		t.Error("the same receipt was undone twice")
+	}
+}
+
+func TestFormatRupiah(t *testing.T) {
+	tests := map[float64]string{
+		0:        "Rp0",
+		950:      "Rp950",
+		188500:   "Rp188.500",
+		1250000:  "Rp1.250.000",
+		-25000.4: "-Rp25.000",
+	}
+	for in, want := range tests {
+		if got := FormatRupiah(in); got != want {
+			t.Errorf("FormatRupiah(%v) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Mutation is one write operation applied to a MemorySheetStore.
+type Mutation struct {
+	Op        string          `json:"op"` // "create", "write", "append", "delete"
+	SheetName string          `json:"sheetName"`
+	Range     string          `json:"range,omitempty"`
+	Values    [][]interface{} `json:"values,omitempty"`
+}
+
+// MemorySheetStore is an in-memory SheetStore. It mimics the subset of the
+// Sheets API behaviour the tools rely on and records every mutation so
+// callers can assert on what a conversation wrote.
+type MemorySheetStore struct {
+	mu        sync.Mutex
+	sheets    map[string]*memorySheet
+	order     []string
+	nextID    int64
+	mutations []Mutation
+}
+
+type memorySheet struct {
+	id   int64
+	rows [][]interface{}
+}
+
+func NewMemorySheetStore() *MemorySheetStore {
+	return &MemorySheetStore{
+		sheets: make(map[string]*memorySheet),
+		nextID: 1,
+	}
+}
+
+// Seed creates (or replaces) a sheet with the given rows without recording
+// a mutation. Use it to set up the spreadsheet a conversation starts from.
+func (m *MemorySheetStore) Seed(sheetName string, rows [][]interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sheet := m.sheetLocked(sheetName)
+	sheet.rows = copyRows(rows)
+}
+
+// Rows returns a copy of every row stored in a sheet.
+func (m *MemorySheetStore) Rows(sheetName string) [][]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sheet, ok := m.sheets[sheetName]
+	if !ok {
+		return nil
+	}
+	return copyRows(sheet.rows)
+}
+
+// Mutations returns the mutations applied so far, in order.
+func (m *MemorySheetStore) Mutations() []Mutation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Mutation(nil), m.mutations...)
+}
+
+func (m *MemorySheetStore) Read(ctx context.Context, sheetName, rangeNotation string) ([][]interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sheet, ok := m.sheets[sheetName]
+	if !ok {
+		return nil, fmt.Errorf("read failed: Unable to parse range: '%s'!%s", sheetName, rangeNotation)
+	}
+
+	r, err := parseA1Range(rangeNotation)
+	if err != nil {
+		return nil, fmt.Errorf("read failed: %w", err)
+	}
+
+	var out [][]interface{}
+	for i := r.startRow; i < len(sheet.rows) && (r.endRow < 0 || i <= r.endRow); i++ {
+		row := sheet.rows[i]
+		var cells []interface{}
+		for j := r.startCol; j < len(row) && (r.endCol < 0 || j <= r.endCol); j++ {
+			cells = append(cells, formatCell(row[j]))
+		}
+		cells = trimTrailingEmpty(cells)
+		out = append(out, cells)
+	}
+
+	// Sheets API omits trailing empty rows
+	for len(out) > 0 && len(out[len(out)-1]) == 0 {
+		out = out[:len(out)-1]
+	}
+	return out, nil
+}
+
+func (m *MemorySheetStore) Write(ctx context.Context, sheetName, rangeNotation string, values [][]interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sheet, ok := m.sheets[sheetName]
+	if !ok {
+		return fmt.Errorf("write failed: Unable to parse range: '%s'!%s", sheetName, rangeNotation)
+	}
+
+	r, err := parseA1Range(rangeNotation)
+	if err != nil {
+		return fmt.Errorf("write failed: %w", err)
+	}
+
+	for i, row := range values {
+		rowIdx := r.startRow + i
+		for len(sheet.rows) <= rowIdx {
+			sheet.rows = append(sheet.rows, nil)
+		}
+		for j, val := range row {
+			colIdx := r.startCol + j
+			for len(sheet.rows[rowIdx]) <= colIdx {
+				sheet.rows[rowIdx] = append(sheet.rows[rowIdx], "")
+			}
+			sheet.rows[rowIdx][colIdx] = val
+		}
+	}
+
+	m.mutations = append(m.mutations, Mutation{
+		Op:        "write",
+		SheetName: sheetName,
+		Range:     rangeNotation,
+		Values:    copyRows(values),
+	})
+	return nil
+}
+
+func (m *MemorySheetStore) Append(ctx context.Context, sheetName string, values [][]interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sheet, ok := m.sheets[sheetName]
+	if !ok {
+		return fmt.Errorf("append failed: Unable to parse range: '%s'", sheetName)
+	}
+
+	sheet.rows = append(sheet.rows, copyRows(values)...)
+	m.mutations = append(m.mutations, Mutation{
+		Op:        "append",
+		SheetName: sheetName,
+		Values:    copyRows(values),
+	})
+	return nil
+}
+
+func (m *MemorySheetStore) Create(ctx context.Context, title string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.sheets[title]; exists {
+		return 0, fmt.Errorf("create sheet failed: a sheet with the name \"%s\" already exists", title)
+	}
+
+	sheet := m.sheetLocked(title)
+	m.mutations = append(m.mutations, Mutation{Op: "create", SheetName: title})
+	return sheet.id, nil
+}
+
+func (m *MemorySheetStore) ListSheets(ctx context.Context) ([]SheetInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sheets []SheetInfo
+	for _, title := range m.order {
+		sheet := m.sheets[title]
+		colCount := 0
+		for _, row := range sheet.rows {
+			if len(row) > colCount {
+				colCount = len(row)
+			}
+		}
+		sheets = append(sheets, SheetInfo{
+			Title:    title,
+			SheetID:  sheet.id,
+			RowCount: int64(len(sheet.rows)),
+			ColCount: int64(colCount),
+			IsEmpty:  len(sheet.rows) == 0,
+		})
+	}
+	return sheets, nil
+}
+
+func (m *MemorySheetStore) DeleteRows(ctx context.Context, sheetName string, from, to int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sheet, ok := m.sheets[sheetName]
+	if !ok {
+		return fmt.Errorf("delete rows failed: sheet '%s' not found", sheetName)
+	}
+	if from < 1 || to < from || to > len(sheet.rows) {
+		return fmt.Errorf("delete rows failed: rows %d-%d out of range", from, to)
+	}
+
+	sheet.rows = append(sheet.rows[:from-1], sheet.rows[to:]...)
+	m.mutations = append(m.mutations, Mutation{
+		Op:        "delete",
+		SheetName: sheetName,
+		Range:     fmt.Sprintf("%d:%d", from, to),
+	})
+	return nil
+}
+
+func (m *MemorySheetStore) FormatHeader(ctx context.Context, sheetID int64, colCount int) error {
+	return nil
+}
+
+func (m *MemorySheetStore) GetLastRowNumber(ctx context.Context, sheetName string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sheet, ok := m.sheets[sheetName]
+	if !ok {
+		return 0, fmt.Errorf("sheet '%s' not found", sheetName)
+	}
+	if len(sheet.rows) <= 1 {
+		return 0, nil
+	}
+
+	last := sheet.rows[len(sheet.rows)-1]
+	if len(last) == 0 {
+		return 0, nil
+	}
+
+	var lastNum int
+	fmt.Sscanf(fmt.Sprintf("%v", last[0]), "%d", &lastNum)
+	return lastNum, nil
+}
+
+func (m *MemorySheetStore) sheetLocked(title string) *memorySheet {
+	sheet, ok := m.sheets[title]
+	if !ok {
+		sheet = &memorySheet{id: m.nextID}
+		m.nextID++
+		m.sheets[title] = sheet
+		m.order = append(m.order, title)
+	}
+	return sheet
+}
+
+// === A1 notation helpers ===
+
+// a1Range is a zero-based, inclusive cell range. -1 means unbounded.
+type a1Range struct {
+	startRow, startCol int
+	endRow, endCol     int
+}
+
+func parseA1Range(notation string) (a1Range, error) {
+	notation = strings.TrimSpace(notation)
+	if notation == "" {
+		return a1Range{endRow: -1, endCol: -1}, nil
+	}
+
+	start, end, hasEnd := strings.Cut(notation, ":")
+	sr, sc, err := parseA1Cell(start)
+	if err != nil {
+		return a1Range{}, err
+	}
+	if !hasEnd {
+		// Single cell: "A1"; bare column "A" means the whole column
+		er, ec := sr, sc
+		if er == -1 {
+			sr = 0
+		}
+		return a1Range{startRow: sr, startCol: sc, endRow: er, endCol: ec}, nil
+	}
+
+	er, ec, err := parseA1Cell(end)
+	if err != nil {
+		return a1Range{}, err
+	}
+	if sr == -1 {
+		sr = 0
+	}
+	return a1Range{startRow: sr, startCol: sc, endRow: er, endCol: ec}, nil
+}
+
+// parseA1Cell parses "B3" into (2, 1). A missing row ("B") yields row -1.
+func parseA1Cell(cell string) (int, int, error) {
+	cell = strings.ToUpper(strings.TrimSpace(cell))
+
+	i := 0
+	col := 0
+	for i < len(cell) && cell[i] >= 'A' && cell[i] <= 'Z' {
+		col = col*26 + int(cell[i]-'A'+1)
+		i++
+	}
+	if i == 0 {
+		return 0, 0, fmt.Errorf("invalid range %q", cell)
+	}
+
+	if i == len(cell) {
+		return -1, col - 1, nil
+	}
+
+	var row int
+	if _, err := fmt.Sscanf(cell[i:], "%d", &row); err != nil || row < 1 {
+		return 0, 0, fmt.Errorf("invalid range %q", cell)
+	}
+	return row - 1, col - 1, nil
+}
+
+func formatCell(val interface{}) interface{} {
+	if val == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", val)
+}
+
+func trimTrailingEmpty(cells []interface{}) []interface{} {
+	for len(cells) > 0 && isEmpty(cells[len(cells)-1]) {
+		cells = cells[:len(cells)-1]
+	}
+	return cells
+}
+
+func copyRows(rows [][]interface{}) [][]interface{} {
+	out := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		out[i] = append([]interface{}(nil), row...)
+	}
+	return out
+}
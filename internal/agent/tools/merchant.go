@@ -0,0 +1,255 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// minMerchantSimilarity is the edit-distance similarity needed for a typo
+// match ("indomart" → "Indomaret"). Longer raw names ("Indomaret Point")
+// only match through an alias, so distinct branches or merchants that share
+// a word are never merged.
+const minMerchantSimilarity = 0.85
+
+// merchantNoiseWords are dropped before matching.
+var merchantNoiseWords = map[string]bool{
+	"pt": true, "tbk": true, "cv": true, "ud": true, "persero": true,
+}
+
+// MerchantEntry is one canonical merchant and the raw names mapped to it.
+type MerchantEntry struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// MerchantDictionary maps raw extracted merchant names to canonical ones.
+// It is backed by a JSON file; an empty path keeps it in memory only.
+type MerchantDictionary struct {
+	path    string
+	mu      sync.RWMutex
+	entries []MerchantEntry
+}
+
+type merchantFile struct {
+	Merchants []MerchantEntry `json:"merchants"`
+}
+
+var merchantDict = &MerchantDictionary{}
+
+// InitMerchantAliases loads MERCHANT_ALIASES_PATH (default:
+// config/merchant_aliases.json). A missing file starts an empty dictionary
+// that is created on the first added alias.
+func InitMerchantAliases() error {
+	path := os.Getenv("MERCHANT_ALIASES_PATH")
+	if path == "" {
+		path = "config/merchant_aliases.json"
+	}
+
+	dict, err := LoadMerchantDictionary(path)
+	if err != nil {
+		return err
+	}
+	merchantDict = dict
+	return nil
+}
+
+func LoadMerchantDictionary(path string) (*MerchantDictionary, error) {
+	dict := &MerchantDictionary{path: path}
+	if path == "" {
+		return dict, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return dict, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read merchant aliases: %w", err)
+	}
+
+	var file merchantFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	dict.entries = file.Merchants
+	return dict, nil
+}
+
+// CurrentMerchantDictionary returns the active dictionary.
+func CurrentMerchantDictionary() *MerchantDictionary {
+	return merchantDict
+}
+
+// SetMerchantDictionary replaces the active dictionary.
+func SetMerchantDictionary(dict *MerchantDictionary) {
+	merchantDict = dict
+}
+
+// InMemoryCopy returns a copy of the dictionary that never writes to disk.
+func (d *MerchantDictionary) InMemoryCopy() *MerchantDictionary {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	entries := make([]MerchantEntry, len(d.entries))
+	for i, e := range d.entries {
+		entries[i] = MerchantEntry{Name: e.Name, Aliases: append([]string(nil), e.Aliases...)}
+	}
+	return &MerchantDictionary{entries: entries}
+}
+
+// Canonical returns the canonical name for raw, or raw (trimmed) when
+// nothing matches.
+func (d *MerchantDictionary) Canonical(raw string) string {
+	raw = strings.TrimSpace(raw)
+	key := normalizeMerchant(raw)
+	if key == "" {
+		return raw
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	best, bestScore := "", 0.0
+	for _, entry := range d.entries {
+		for _, name := range append([]string{entry.Name}, entry.Aliases...) {
+			if score := merchantScore(key, normalizeMerchant(name)); score > bestScore {
+				best, bestScore = entry.Name, score
+			}
+		}
+	}
+
+	if bestScore >= minMerchantSimilarity {
+		return best
+	}
+	return raw
+}
+
+// AddAlias maps alias to merchant, creating the merchant if needed, and
+// saves the dictionary. It fails if alias already belongs to another merchant.
+func (d *MerchantDictionary) AddAlias(alias, merchant string) error {
+	alias = strings.TrimSpace(alias)
+	merchant = strings.TrimSpace(merchant)
+	if alias == "" || merchant == "" {
+		return fmt.Errorf("alias and merchant are required")
+	}
+	aliasKey := normalizeMerchant(alias)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	idx := -1
+	for i, entry := range d.entries {
+		if strings.EqualFold(entry.Name, merchant) {
+			idx = i
+		}
+		for _, a := range append([]string{entry.Name}, entry.Aliases...) {
+			if normalizeMerchant(a) == aliasKey && !strings.EqualFold(entry.Name, merchant) {
+				return fmt.Errorf("'%s' is already mapped to '%s'", alias, entry.Name)
+			}
+		}
+	}
+
+	if idx < 0 {
+		d.entries = append(d.entries, MerchantEntry{Name: merchant})
+		idx = len(d.entries) - 1
+	}
+
+	entry := &d.entries[idx]
+	for _, a := range entry.Aliases {
+		if normalizeMerchant(a) == aliasKey {
+			return nil
+		}
+	}
+	if normalizeMerchant(entry.Name) != aliasKey {
+		entry.Aliases = append(entry.Aliases, alias)
+	}
+
+	return d.saveLocked()
+}
+
+func (d *MerchantDictionary) saveLocked() error {
+	if d.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(d.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create alias directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(merchantFile{Merchants: d.entries}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode merchant aliases: %w", err)
+	}
+	return os.WriteFile(d.path, append(data, '\n'), 0o644)
+}
+
+// CanonicalMerchant maps a raw merchant name using the active dictionary.
+func CanonicalMerchant(raw string) string {
+	return merchantDict.Canonical(raw)
+}
+
+// AddMerchantAlias adds an alias to the active dictionary.
+func AddMerchantAlias(alias, merchant string) error {
+	return merchantDict.AddAlias(alias, merchant)
+}
+
+// merchantScore is the edit-distance similarity of two normalized names,
+// 1 when they are equal.
+func merchantScore(raw, known string) float64 {
+	if raw == "" || known == "" {
+		return 0
+	}
+	if raw == known {
+		return 1
+	}
+	return similarity(raw, known)
+}
+
+func normalizeMerchant(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, s)
+
+	var words []string
+	for _, w := range strings.Fields(s) {
+		if !merchantNoiseWords[w] {
+			words = append(words, w)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// similarity is 1 - levenshtein(a, b) / max(len(a), len(b)).
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return 1 - float64(prev[len(rb)])/float64(max(len(ra), len(rb)))
+}
@@ -0,0 +1,45 @@
+package tools
+
+import "testing"
+
+func TestMerchantCanonical(t *testing.T) {
+	dict := &MerchantDictionary{entries: []MerchantEntry{
+		{Name: "Indomaret", Aliases: []string{"INDOMARET PT INDOMARCO", "Indomaret Point"}},
+		{Name: "Kopi Kenangan"},
+		{Name: "Starbucks"},
+	}}
+
+	tests := []struct {
+		raw, want string
+	}{
+		{"indomaret", "Indomaret"},
+		{"PT. INDOMARET", "Indomaret"},
+		{"Indomaret Point", "Indomaret"},
+		{"INDOMART", "Indomaret"},
+		{"Starbuck", "Starbucks"},
+		// Sharing a word is not enough
+		{"Indomaret Point Kemang", "Indomaret Point Kemang"},
+		{"Kopi Kenangan Mantan", "Kopi Kenangan Mantan"},
+		{"Kopi Janji Jiwa", "Kopi Janji Jiwa"},
+		{"  Warung Bu Sri ", "Warung Bu Sri"},
+	}
+	for _, tt := range tests {
+		if got := dict.Canonical(tt.raw); got != tt.want {
+			t.Errorf("Canonical(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestMerchantAddAlias(t *testing.T) {
+	dict := &MerchantDictionary{entries: []MerchantEntry{{Name: "Indomaret"}}}
+
+	if err := dict.AddAlias("Indomaret Point Kemang", "Indomaret"); err != nil {
+		t.Fatal(err)
+	}
+	if got := dict.Canonical("indomaret point kemang"); got != "Indomaret" {
+		t.Errorf("Canonical after AddAlias = %q", got)
+	}
+	if err := dict.AddAlias("Indomaret Point Kemang", "Alfamart"); err == nil {
+		t.Error("alias of another merchant was remapped")
+	}
+}
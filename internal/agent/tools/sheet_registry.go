@@ -0,0 +1,246 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// SheetRegistry maps user IDs (e.g. "tg_<chatID>") to their own
+// spreadsheet. Users without an entry use the default SPREADSHEET_ID.
+type SheetRegistry struct {
+	path    string
+	newFunc func(ctx context.Context, spreadsheetID string) (SheetStore, error)
+	// defaultID is SPREADSHEET_ID; only owners may connect it explicitly.
+	defaultID string
+
+	mu      sync.Mutex
+	users   map[string]string
+	clients map[string]SheetStore
+}
+
+type registryFile struct {
+	Users map[string]string `json:"users"`
+}
+
+var registry *SheetRegistry
+
+var (
+	// ErrSpreadsheetInUse: the spreadsheet is already connected by another
+	// user.
+	ErrSpreadsheetInUse = errors.New("spreadsheet is already connected to another chat")
+	// ErrDefaultSpreadsheet: only owners may connect the shared default
+	// spreadsheet.
+	ErrDefaultSpreadsheet = errors.New("the shared default spreadsheet can only be connected by an owner")
+)
+
+// spreadsheetURL extracts the ID from ".../spreadsheets/d/<id>/edit".
+var spreadsheetURL = regexp.MustCompile(`/spreadsheets/d/([a-zA-Z0-9_-]+)`)
+
+var spreadsheetIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{20,}$`)
+
+type userIDKey struct{}
+
+// WithUserID marks ctx as acting for userID so the tools use that user's
+// spreadsheet.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+func userIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(userIDKey{}).(string)
+	return id
+}
+
+// LoadSheetRegistry reads the user → spreadsheet map at path. newFunc opens
+// a store for a spreadsheet ID; stores are cached per spreadsheet.
+func LoadSheetRegistry(path string, newFunc func(ctx context.Context, spreadsheetID string) (SheetStore, error)) (*SheetRegistry, error) {
+	reg := &SheetRegistry{
+		path:    path,
+		newFunc: newFunc,
+		users:   make(map[string]string),
+		clients: make(map[string]SheetStore),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return reg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sheet registry: %w", err)
+	}
+
+	var file registryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for user, id := range file.Users {
+		reg.users[user] = id
+	}
+	return reg, nil
+}
+
+// SetSheetRegistry replaces the active registry; nil routes every user to
+// the default store.
+func SetSheetRegistry(reg *SheetRegistry) {
+	registry = reg
+}
+
+// SpreadsheetFor returns the spreadsheet registered for userID.
+func (r *SheetRegistry) SpreadsheetFor(userID string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id, ok := r.users[userID]
+	return id, ok
+}
+
+// Connect registers spreadsheet (an ID or a Google Sheets URL) for userID
+// after checking that the service account can open it. A spreadsheet
+// belongs to one user, and the default one is reserved for owners.
+func (r *SheetRegistry) Connect(ctx context.Context, userID, spreadsheet string, owner bool) (string, error) {
+	id, err := ParseSpreadsheetID(spreadsheet)
+	if err != nil {
+		return "", err
+	}
+	if id == r.defaultID && !owner {
+		return "", ErrDefaultSpreadsheet
+	}
+	if r.connectedByOther(userID, id) {
+		return "", ErrSpreadsheetInUse
+	}
+
+	store, err := r.store(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if _, err := store.ListSheets(ctx); err != nil {
+		return "", fmt.Errorf("cannot open spreadsheet %s (is it shared with the service account as editor?): %w", id, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Checked again: another chat may have connected it during ListSheets
+	if r.connectedByOtherLocked(userID, id) {
+		return "", ErrSpreadsheetInUse
+	}
+	r.users[userID] = id
+	if err := r.saveLocked(); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (r *SheetRegistry) connectedByOther(userID, spreadsheetID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.connectedByOtherLocked(userID, spreadsheetID)
+}
+
+func (r *SheetRegistry) connectedByOtherLocked(userID, spreadsheetID string) bool {
+	for user, id := range r.users {
+		if id == spreadsheetID && user != userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *SheetRegistry) storeFor(ctx context.Context, userID string) (SheetStore, bool, error) {
+	id, ok := r.SpreadsheetFor(userID)
+	if !ok {
+		return nil, false, nil
+	}
+	store, err := r.store(ctx, id)
+	return store, true, err
+}
+
+func (r *SheetRegistry) store(ctx context.Context, spreadsheetID string) (SheetStore, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if store, ok := r.clients[spreadsheetID]; ok {
+		return store, nil
+	}
+	store, err := r.newFunc(ctx, spreadsheetID)
+	if err != nil {
+		return nil, err
+	}
+	r.clients[spreadsheetID] = store
+	return store, nil
+}
+
+func (r *SheetRegistry) saveLocked() error {
+	if r.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create registry directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(registryFile{Users: r.users}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sheet registry: %w", err)
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to save sheet registry: %w", err)
+	}
+	return nil
+}
+
+// ParseSpreadsheetID accepts a bare spreadsheet ID or a Google Sheets URL.
+func ParseSpreadsheetID(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if m := spreadsheetURL.FindStringSubmatch(s); m != nil {
+		return m[1], nil
+	}
+	if spreadsheetIDPattern.MatchString(s) {
+		return s, nil
+	}
+	return "", fmt.Errorf("'%s' is not a Google Sheets link or spreadsheet ID", s)
+}
+
+// ConnectSpreadsheet registers a spreadsheet for userID in the active
+// registry; owner allows connecting the default spreadsheet.
+func ConnectSpreadsheet(ctx context.Context, userID, spreadsheet string, owner bool) (string, error) {
+	if registry == nil {
+		return "", fmt.Errorf("per-user spreadsheets are not enabled")
+	}
+	return registry.Connect(ctx, userID, spreadsheet, owner)
+}
+
+// ConnectedSpreadsheet returns the spreadsheet registered for userID.
+func ConnectedSpreadsheet(userID string) (string, bool) {
+	if registry == nil {
+		return "", false
+	}
+	return registry.SpreadsheetFor(userID)
+}
+
+// storeFor picks the user's spreadsheet from ctx (see WithUserID), falling
+// back to the default store.
+func storeFor(ctx context.Context) (SheetStore, error) {
+	if registry != nil {
+		if userID := userIDFrom(ctx); userID != "" {
+			store, ok, err := registry.storeFor(ctx, userID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open spreadsheet: %w", err)
+			}
+			if ok {
+				return store, nil
+			}
+		}
+	}
+
+	if globalClient == nil {
+		return nil, fmt.Errorf("no spreadsheet connected: ask the user to connect one with /connect <spreadsheet link>")
+	}
+	return globalClient, nil
+}
@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestSheetRegistryConnect(t *testing.T) {
+	ctx := context.Background()
+	const (
+		defaultID = "default_spreadsheet_id_0000"
+		ownID     = "own_spreadsheet_id_00000000"
+	)
+
+	reg, err := LoadSheetRegistry(filepath.Join(t.TempDir(), "user_sheets.json"), func(ctx context.Context, id string) (SheetStore, error) {
+		return NewMemorySheetStore(), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg.defaultID = defaultID
+
+	if _, err := reg.Connect(ctx, "tg_1", defaultID, false); !errors.Is(err, ErrDefaultSpreadsheet) {
+		t.Errorf("member connecting the default: err = %v, want ErrDefaultSpreadsheet", err)
+	}
+	if _, err := reg.Connect(ctx, "tg_1", defaultID, true); err != nil {
+		t.Errorf("owner connecting the default: %v", err)
+	}
+
+	if _, err := reg.Connect(ctx, "tg_2", "https://docs.google.com/spreadsheets/d/"+ownID+"/edit", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reg.Connect(ctx, "tg_3", ownID, false); !errors.Is(err, ErrSpreadsheetInUse) {
+		t.Errorf("connecting another chat's spreadsheet: err = %v, want ErrSpreadsheetInUse", err)
+	}
+	// Reconnecting your own spreadsheet is fine
+	if _, err := reg.Connect(ctx, "tg_2", ownID, false); err != nil {
+		t.Errorf("reconnecting own spreadsheet: %v", err)
+	}
+	if id, _ := reg.SpreadsheetFor("tg_3"); id != "" {
+		t.Errorf("tg_3 spreadsheet = %q, want none", id)
+	}
+}
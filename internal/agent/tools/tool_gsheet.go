@@ -8,14 +8,48 @@ import (
 	"time"
 )
 
+// now is the clock used for sheet names and default receipt dates.
+var now = time.Now
+
+// SetClock overrides the clock used by the tools. Pass nil to restore
+// time.Now. Replays use it to reproduce the recording date.
+func SetClock(clock func() time.Time) {
+	if clock == nil {
+		clock = time.Now
+	}
+	now = clock
+}
+
+type authorKey struct{}
+
+// WithAuthor records who is adding transactions, e.g. the group member
+// who sent the receipt. Appended rows get it in the added_by column.
+func WithAuthor(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, authorKey{}, name)
+}
+
+// AuthorFrom returns the name set with WithAuthor, "" if none.
+func AuthorFrom(ctx context.Context) string {
+	name, _ := ctx.Value(authorKey{}).(string)
+	return name
+}
+
 // === Public API untuk ADK Tools ===
 
 func ReadFromSheet(ctx context.Context, sheetName, rangeNotation string) ([][]interface{}, error) {
-	return globalClient.Read(ctx, sheetName, rangeNotation)
+	store, err := storeFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.Read(ctx, sheetName, rangeNotation)
 }
 
 func WriteToSheet(ctx context.Context, sheetName, rangeNotation string, values [][]interface{}) error {
-	return globalClient.Write(ctx, sheetName, rangeNotation, values)
+	store, err := storeFor(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Write(ctx, sheetName, rangeNotation, values)
 }
 
 func AppendToSheet(ctx context.Context, sheetName string, values [][]interface{}) error {
@@ -23,51 +57,120 @@ func AppendToSheet(ctx context.Context, sheetName string, values [][]interface{}
 		return fmt.Errorf("no data to append")
 	}
 
+	store, err := storeFor(ctx)
+	if err != nil {
+		return err
+	}
+
 	// Normalize & validate rows
-	normalized, err := normalizeRows(ctx, sheetName, values)
+	normalized, err := normalizeRows(ctx, store, sheetName, values)
 	if err != nil {
 		return err
 	}
 
-	return globalClient.Append(ctx, sheetName, normalized)
+	if author := AuthorFrom(ctx); author != "" {
+		for _, row := range normalized {
+			row[ColAddedBy] = author
+		}
+		ensureAddedByHeader(ctx, store, sheetName)
+	}
+
+	if err := store.Append(ctx, sheetName, normalized); err != nil {
+		return err
+	}
+	recordAppend(ctx, sheetName, normalized)
+	return nil
 }
 
-func CreateNewSheet(ctx context.Context, sheetTitle string) error {
+// ValidateRows checks rows the way AppendToSheet would, without touching the
+// sheet, so invalid data is rejected before it is proposed to the user.
+func ValidateRows(values [][]interface{}) error {
+	if len(values) == 0 {
+		return fmt.Errorf("no data to append")
+	}
+	for i, row := range values {
+		if _, err := normalizeRow(row, 0, i+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateNewSheet creates "Transaction_{title}_{YYYYMMDD}" with the header
+// row and returns its full name.
+func CreateNewSheet(ctx context.Context, sheetTitle string) (string, error) {
+	return createSheet(ctx, sheetTitle)
+}
+
+// TodaySheet returns today's transaction sheet, creating
+// "Transaction_Tracker_{YYYYMMDD}" if there is none, the same choice the
+// agent makes when the user names no sheet.
+func TodaySheet(ctx context.Context) (string, error) {
+	store, err := storeFor(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	sheets, err := store.ListSheets(ctx)
+	if err != nil {
+		return "", err
+	}
+	today := now().Format("20060102")
+	for _, sheet := range sheets {
+		if strings.HasPrefix(sheet.Title, "Transaction_") && sheetDate(sheet.Title) == today {
+			return sheet.Title, nil
+		}
+	}
+	return createSheet(ctx, "Tracker")
+}
+
+func ListSheetsWithInfo(ctx context.Context) ([]SheetInfo, error) {
+	store, err := storeFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.ListSheets(ctx)
+}
+
+// === Internal helpers ===
+
+// createSheet creates a transaction sheet with the header row and returns
+// its full name.
+func createSheet(ctx context.Context, sheetTitle string) (string, error) {
 	// Format: Transaction_{title}_{YYYYMMDD}
-	timestamp := time.Now().Format("20060102")
+	timestamp := now().Format("20060102")
 	formattedTitle := fmt.Sprintf("Transaction_%s_%s", sheetTitle, timestamp)
 
+	store, err := storeFor(ctx)
+	if err != nil {
+		return "", err
+	}
+
 	// Create sheet
-	sheetID, err := globalClient.Create(ctx, formattedTitle)
+	sheetID, err := store.Create(ctx, formattedTitle)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	// Write header
 	headerRange := fmt.Sprintf("A1:%s1", columnLetter(len(DefaultHeaders)))
 	headerValues := [][]interface{}{toInterfaceSlice(DefaultHeaders)}
 
-	if err := globalClient.Write(ctx, formattedTitle, headerRange, headerValues); err != nil {
-		return fmt.Errorf("failed to write header: %w", err)
+	if err := store.Write(ctx, formattedTitle, headerRange, headerValues); err != nil {
+		return "", fmt.Errorf("failed to write header: %w", err)
 	}
 
 	// Format header (non-critical, don't fail)
-	if err := globalClient.FormatHeader(ctx, sheetID, len(DefaultHeaders)); err != nil {
+	if err := store.FormatHeader(ctx, sheetID, len(DefaultHeaders)); err != nil {
 		log.Printf("⚠ Warning: failed to format header: %v", err)
 	}
 
 	log.Printf("✓ Created sheet: %s", formattedTitle)
-	return nil
+	return formattedTitle, nil
 }
 
-func ListSheetsWithInfo(ctx context.Context) ([]SheetInfo, error) {
-	return globalClient.ListSheets(ctx)
-}
-
-// === Internal helpers ===
-
-func normalizeRows(ctx context.Context, sheetName string, rows [][]interface{}) ([][]interface{}, error) {
-	lastNo, _ := globalClient.GetLastRowNumber(ctx, sheetName)
+func normalizeRows(ctx context.Context, store SheetStore, sheetName string, rows [][]interface{}) ([][]interface{}, error) {
+	lastNo, _ := store.GetLastRowNumber(ctx, sheetName)
 	nextNo := lastNo + 1
 
 	normalized := make([][]interface{}, 0, len(rows))
@@ -85,8 +188,8 @@ func normalizeRows(ctx context.Context, sheetName string, rows [][]interface{})
 }
 
 func normalizeRow(row []interface{}, nextNo, rowIndex int) ([]interface{}, error) {
-	// Ensure 11 columns
-	normalized := make([]interface{}, 11)
+	// Ensure the standard columns
+	normalized := make([]interface{}, len(DefaultHeaders))
 	copy(normalized, row)
 
 	// Auto-fill defaults
@@ -97,7 +200,7 @@ func normalizeRow(row []interface{}, nextNo, rowIndex int) ([]interface{}, error
 		normalized[ColQty] = 1
 	}
 	if isEmpty(normalized[ColReceiptDate]) {
-		normalized[ColReceiptDate] = time.Now().Format(time.RFC3339)
+		normalized[ColReceiptDate] = now().Format(time.RFC3339)
 	}
 	if isEmpty(normalized[ColInputSource]) {
 		normalized[ColInputSource] = "manual"
@@ -117,9 +220,34 @@ func normalizeRow(row []interface{}, nextNo, rowIndex int) ([]interface{}, error
 		}
 	}
 
+	// Map raw merchant names to their canonical form
+	normalized[ColMerchant] = CanonicalMerchant(fmt.Sprintf("%v", normalized[ColMerchant]))
+
+	// Rules first, the model's guess only as fallback
+	category, err := resolveCategory(normalized[ColMerchant], normalized[ColItemName], normalized[ColCategory])
+	if err != nil {
+		return nil, fmt.Errorf("row %d: %w", rowIndex, err)
+	}
+	normalized[ColCategory] = category
+
 	return normalized, nil
 }
 
+// ensureAddedByHeader names the added_by column of sheets created before
+// it existed. Failures only leave the header blank.
+func ensureAddedByHeader(ctx context.Context, store SheetStore, sheetName string) {
+	cell := fmt.Sprintf("%s1", columnLetter(ColAddedBy+1))
+	rows, err := store.Read(ctx, sheetName, fmt.Sprintf("A1:%s", cell))
+	if err != nil || len(rows) == 0 || !isHeaderRow(rows[0]) || len(rows[0]) > ColAddedBy {
+		return
+	}
+
+	header := [][]interface{}{{DefaultHeaders[ColAddedBy]}}
+	if err := store.Write(ctx, sheetName, fmt.Sprintf("%s:%s", cell, cell), header); err != nil {
+		log.Printf("⚠ Warning: failed to add added_by header to %s: %v", sheetName, err)
+	}
+}
+
 func isEmpty(val interface{}) bool {
 	if val == nil {
 		return true
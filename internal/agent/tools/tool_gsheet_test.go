@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAppendToSheetAddedBy(t *testing.T) {
+	store := NewMemorySheetStore()
+	// A sheet created before the added_by column existed
+	store.Seed("Transaction_Tracker_20260314", [][]interface{}{toInterfaceSlice(DefaultHeaders[:ColAddedBy])})
+	SetSheetStore(store)
+	SetCategoryRules([]CategoryRule{})
+	SetMerchantDictionary(&MerchantDictionary{})
+	defer SetSheetStore(nil)
+
+	row := []interface{}{"", "Nasi", 1, "", 20000, 20000, "Food", "Warung", "2026-03-14", "manual", "R1"}
+	sheet := "Transaction_Tracker_20260314"
+
+	// Private chat: no author, the old header is left alone
+	if err := AppendToSheet(context.Background(), sheet, [][]interface{}{row}); err != nil {
+		t.Fatal(err)
+	}
+	rows := store.Rows(sheet)
+	if len(rows[0]) != ColAddedBy || !isEmpty(rows[1][ColAddedBy]) {
+		t.Fatalf("private append touched added_by: %v", rows)
+	}
+
+	// Group chat: the sender is recorded and the header added
+	ctx := WithAuthor(context.Background(), "Budi")
+	if err := AppendToSheet(ctx, sheet, [][]interface{}{row}); err != nil {
+		t.Fatal(err)
+	}
+	rows = store.Rows(sheet)
+	if got := rows[0][ColAddedBy]; got != DefaultHeaders[ColAddedBy] {
+		t.Errorf("header = %v, want %s", got, DefaultHeaders[ColAddedBy])
+	}
+	if got := rows[2][ColAddedBy]; got != "Budi" {
+		t.Errorf("added_by = %v, want Budi", got)
+	}
+}
@@ -13,12 +13,13 @@ const (
 	ColReceiptDate = 8
 	ColInputSource = 9
 	ColReceiptID   = 10
+	ColAddedBy     = 11 // filled by the backend, not the model
 )
 
 var DefaultHeaders = []string{
 	"no", "item_name", "qty", "unit", "unit_price",
 	"amount", "category", "merchant", "receipt_date",
-	"input_source", "receipt_id",
+	"input_source", "receipt_id", "added_by",
 }
 
 // Sheet info
@@ -81,3 +82,14 @@ type ListSheetsResult struct {
 	Sheets      []SheetInfo `json:"sheets,omitempty"`
 	Error       string      `json:"error,omitempty"`
 }
+
+type AddMerchantAliasArgs struct {
+	Alias    string `json:"alias"`
+	Merchant string `json:"merchant"`
+}
+
+type AddMerchantAliasResult struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
@@ -0,0 +1,26 @@
+package agent
+
+import (
+	adkagent "google.golang.org/adk/agent"
+	"google.golang.org/adk/tool"
+)
+
+// filteredToolset exposes the tools accepted by filter for each invocation.
+type filteredToolset struct {
+	tools  []tool.Tool
+	filter tool.Predicate
+}
+
+func (f *filteredToolset) Name() string {
+	return "financial_tracker_tools"
+}
+
+func (f *filteredToolset) Tools(ctx adkagent.ReadonlyContext) ([]tool.Tool, error) {
+	var allowed []tool.Tool
+	for _, t := range f.tools {
+		if f.filter(ctx, t) {
+			allowed = append(allowed, t)
+		}
+	}
+	return allowed, nil
+}
@@ -4,33 +4,95 @@ import (
 	"context"
 	"os"
 
+	"finagent/internal/agent/hitl"
 	"finagent/internal/agent/tools"
+	"finagent/internal/i18n"
 
 	adkagent "google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
 	"google.golang.org/adk/model/gemini"
 	"google.golang.org/adk/tool"
 	"google.golang.org/genai"
 )
 
+// Options overrides the defaults NewTrackerAgent uses. The zero value gives
+// the production setup: Gemini from GEMINI_MODEL and the Sheets API client.
+type Options struct {
+	// Model replaces the Gemini model (e.g. a replay or recording model).
+	Model model.LLM
+	// SheetStore replaces the Google Sheets client used by the tools.
+	SheetStore tools.SheetStore
+	// CategoryRules and Merchants replace the files at CATEGORY_RULES_PATH
+	// and MERCHANT_ALIASES_PATH, whose defaults are relative to the
+	// repository root. Use an empty, non-nil slice for no rules.
+	CategoryRules []tools.CategoryRule
+	Merchants     *tools.MerchantDictionary
+	// Instruction replaces SystemPrompt (e.g. to evaluate a prompt version).
+	Instruction string
+	// ToolFilter decides per invocation which tools the model sees, e.g.
+	// only query tools for read-only users.
+	ToolFilter tool.Predicate
+
+	BeforeAgentCallbacks []adkagent.BeforeAgentCallback
+	BeforeToolCallbacks  []llmagent.BeforeToolCallback
+	AfterToolCallbacks   []llmagent.AfterToolCallback
+}
+
 func NewTrackerAgent(ctx context.Context, adkToolSheets []tool.Tool) (adkagent.Agent, error) {
-	if err := tools.InitSheetClient(ctx); err != nil {
+	return NewTrackerAgentWithOptions(ctx, adkToolSheets, Options{})
+}
+
+func NewTrackerAgentWithOptions(ctx context.Context, adkToolSheets []tool.Tool, opts Options) (adkagent.Agent, error) {
+	if opts.SheetStore != nil {
+		tools.SetSheetStore(opts.SheetStore)
+	} else if err := tools.InitSheetClient(ctx); err != nil {
 		return nil, err
 	}
 
-	model, err := gemini.NewModel(ctx, os.Getenv("GEMINI_MODEL"), &genai.ClientConfig{
-		APIKey: os.Getenv("GOOGLE_API_KEY"),
-	})
-	if err != nil {
+	if opts.CategoryRules != nil {
+		tools.SetCategoryRules(opts.CategoryRules)
+	} else if err := tools.InitCategoryRules(); err != nil {
+		return nil, err
+	}
+	if opts.Merchants != nil {
+		tools.SetMerchantDictionary(opts.Merchants)
+	} else if err := tools.InitMerchantAliases(); err != nil {
 		return nil, err
 	}
 
+	llm := opts.Model
+	if llm == nil {
+		var err error
+		llm, err = NewGeminiModel(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	instruction := opts.Instruction
+	if instruction == "" {
+		instruction = SystemPrompt
+	}
+
+	agentTools := adkToolSheets
+	var toolsets []tool.Toolset
+	if opts.ToolFilter != nil {
+		agentTools = nil
+		toolsets = []tool.Toolset{&filteredToolset{tools: adkToolSheets, filter: opts.ToolFilter}}
+	}
+
+	// hitl.Gate always runs first so mutating tools cannot skip confirmation
 	trackerAgent, err := llmagent.New(llmagent.Config{
-		Name:        "financial_tracker",
-		Model:       model,
-		Description: "A financial transaction tracker that manages data in Google Sheets",
-		Instruction: SystemPrompt,
-		Tools:       adkToolSheets,
+		Name:                 "financial_tracker",
+		Model:                llm,
+		Description:          "A financial transaction tracker that manages data in Google Sheets",
+		InstructionProvider:  localizedInstruction(instruction),
+		Tools:                agentTools,
+		Toolsets:             toolsets,
+		BeforeAgentCallbacks: opts.BeforeAgentCallbacks,
+		BeforeToolCallbacks:  append([]llmagent.BeforeToolCallback{hitl.Gate}, opts.BeforeToolCallbacks...),
+		AfterToolCallbacks:   opts.AfterToolCallbacks,
 	})
 	if err != nil {
 		return nil, err
@@ -38,3 +100,30 @@ func NewTrackerAgent(ctx context.Context, adkToolSheets []tool.Tool) (adkagent.A
 
 	return trackerAgent, nil
 }
+
+// localizedInstruction appends the reply-language section for the user's
+// language (i18n.StateKey), or a "mirror the user" section when unset, and
+// the summary of compacted turns if any.
+// Providers skip {placeholder} injection, which the prompts do not use.
+func localizedInstruction(base string) llmagent.InstructionProvider {
+	return func(ctx adkagent.ReadonlyContext) (string, error) {
+		state := ctx.ReadonlyState()
+		lang, _ := i18n.FromState(state.Get)
+		instruction := base + "\n" + i18n.Instruction(lang)
+
+		if summary, err := state.Get(SummaryStateKey); err == nil {
+			if s, ok := summary.(string); ok && s != "" {
+				instruction += "\n\n=== EARLIER IN THIS CONVERSATION ===\n\n" + s
+			}
+		}
+		return instruction, nil
+	}
+}
+
+// NewGeminiModel creates the Gemini model configured by GEMINI_MODEL and
+// GOOGLE_API_KEY.
+func NewGeminiModel(ctx context.Context) (model.LLM, error) {
+	return gemini.NewModel(ctx, os.Getenv("GEMINI_MODEL"), &genai.ClientConfig{
+		APIKey: os.Getenv("GOOGLE_API_KEY"),
+	})
+}
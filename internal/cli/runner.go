@@ -4,29 +4,39 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"mime"
-	"os"
-	"path/filepath"
+
+	"finagent/internal/agent/hitl"
+	"finagent/internal/imageprep"
 
 	adkagent "google.golang.org/adk/agent"
 	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
 	"google.golang.org/genai"
 )
 
 type CLIRunner struct {
-	runner    *runner.Runner
-	sessionID string
-	userID    string
+	runner         *runner.Runner
+	sessionService session.Service
+	sessionID      string
+	userID         string
+	imageOptions   imageprep.Options
 }
 
-func NewCLIRunner(r *runner.Runner, sessionID, userID string) *CLIRunner {
+func NewCLIRunner(r *runner.Runner, sessionService session.Service, sessionID, userID string) *CLIRunner {
 	return &CLIRunner{
-		runner:    r,
-		sessionID: sessionID,
-		userID:    userID,
+		runner:         r,
+		sessionService: sessionService,
+		sessionID:      sessionID,
+		userID:         userID,
+		imageOptions:   imageprep.DefaultOptions(),
 	}
 }
 
+// SetImageOptions changes how images are prepared before they are sent.
+func (c *CLIRunner) SetImageOptions(opts imageprep.Options) {
+	c.imageOptions = opts
+}
+
 func (c *CLIRunner) Run(ctx context.Context, text, imagePath string) error {
 	fmt.Printf("\n%s\n", Cyan(fmt.Sprintf("User → %s", text)))
 	if imagePath != "" {
@@ -92,6 +102,9 @@ func (c *CLIRunner) Run(ctx context.Context, text, imagePath string) error {
 									fmt.Printf("%s\n", Gray("   Sheet contains data"))
 								}
 							}
+						} else if status == "pending_confirmation" {
+							msg, _ := resp["message"].(string)
+							fmt.Printf("%s\n", Yellow(fmt.Sprintf("   ⏳ %s", msg)))
 						} else if errMsg, ok := resp["error"].(string); ok {
 							fmt.Printf("%s\n", Red(fmt.Sprintf("   Error: %s", errMsg)))
 						} else {
@@ -107,6 +120,37 @@ func (c *CLIRunner) Run(ctx context.Context, text, imagePath string) error {
 	return nil
 }
 
+// PendingActions returns the mutations waiting for the user's decision.
+func (c *CLIRunner) PendingActions(ctx context.Context) ([]hitl.PendingAction, error) {
+	return hitl.List(ctx, c.sessionService, c.sessionRef())
+}
+
+// ResolveAction commits or cancels a pending action and prints the outcome.
+func (c *CLIRunner) ResolveAction(ctx context.Context, actionID string, approve bool) error {
+	res, err := hitl.Resolve(ctx, c.sessionService, c.sessionRef(), actionID, approve)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !approve:
+		fmt.Printf("%s\n", Gray(fmt.Sprintf("✗ Cancelled: %s", res.Action.Summary)))
+	case res.Err != nil:
+		fmt.Printf("%s\n", Red(fmt.Sprintf("❌ Failed: %v", res.Err)))
+	default:
+		fmt.Printf("%s\n", Green(fmt.Sprintf("✓ %s", res.Message)))
+	}
+	return nil
+}
+
+func (c *CLIRunner) sessionRef() hitl.SessionRef {
+	return hitl.SessionRef{
+		AppName:   "financial_tracker",
+		UserID:    c.userID,
+		SessionID: c.sessionID,
+	}
+}
+
 func (c *CLIRunner) createContent(text, imagePath string) (*genai.Content, error) {
 	parts := []*genai.Part{}
 
@@ -115,17 +159,15 @@ func (c *CLIRunner) createContent(text, imagePath string) (*genai.Content, error
 	}
 
 	if imagePath != "" {
-		imageData, err := os.ReadFile(imagePath)
+		img, err := imageprep.PrepareFile(imagePath, c.imageOptions)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read image: %w", err)
+			return nil, err
 		}
-
-		mimeType := mime.TypeByExtension(filepath.Ext(imagePath))
-		if mimeType == "" {
-			mimeType = "application/octet-stream"
+		if img.Width > 0 {
+			fmt.Printf("%s\n", Gray(fmt.Sprintf("Image → %dx%d %s, %d KB (was %d KB)", img.Width, img.Height, img.MimeType, len(img.Data)/1024, img.Original/1024)))
 		}
 
-		parts = append(parts, genai.NewPartFromBytes(imageData, mimeType))
+		parts = append(parts, genai.NewPartFromBytes(img.Data, img.MimeType))
 	}
 
 	return &genai.Content{
@@ -0,0 +1,83 @@
+// Package eval measures receipt extraction quality against golden files.
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Receipt is the normalized content of one receipt. Golden files use it
+// directly; extraction results are converted into it for scoring.
+type Receipt struct {
+	Merchant string  `json:"merchant"`
+	Date     string  `json:"date,omitempty"` // YYYY-MM-DD, empty if the receipt has none
+	Items    []Item  `json:"items"`
+	Total    float64 `json:"total"`
+}
+
+type Item struct {
+	Name   string  `json:"name"`
+	Qty    float64 `json:"qty,omitempty"`
+	Amount float64 `json:"amount"`
+}
+
+// Case is one image paired with its expected extraction.
+type Case struct {
+	Name      string
+	ImagePath string
+	Expected  Receipt
+}
+
+var imageExts = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".webp": true,
+}
+
+// LoadCases finds every image in dir that has a golden file with the same
+// base name (receipt.jpg → receipt.json). Images without one are returned
+// in skipped.
+func LoadCases(dir string) (cases []Case, skipped []string, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read eval directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || !imageExts[ext] {
+			continue
+		}
+
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		goldenPath := filepath.Join(dir, name+".json")
+
+		data, err := os.ReadFile(goldenPath)
+		if os.IsNotExist(err) {
+			skipped = append(skipped, entry.Name())
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", goldenPath, err)
+		}
+
+		var expected Receipt
+		if err := json.Unmarshal(data, &expected); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %w", goldenPath, err)
+		}
+
+		cases = append(cases, Case{
+			Name:      name,
+			ImagePath: filepath.Join(dir, entry.Name()),
+			Expected:  expected,
+		})
+	}
+
+	sort.Slice(cases, func(i, j int) bool { return cases[i].Name < cases[j].Name })
+	return cases, skipped, nil
+}
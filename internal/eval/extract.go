@@ -0,0 +1,225 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"finagent/internal/agent"
+	"finagent/internal/agent/hitl"
+	"finagent/internal/agent/tools"
+
+	adkagent "google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// Extractor runs the tracker agent on one receipt image and reads back the
+// rows it appended. It drives the same prompt and tools as production, with
+// an in-memory sheet store instead of the Sheets API.
+type Extractor struct {
+	Model       model.LLM
+	Instruction string // empty uses agent.SystemPrompt
+	Prompt      string // user text sent with the image
+	Confirm     string // reply sent when the agent asks before proposing rows
+	MaxTurns    int    // confirmation replies before giving up
+}
+
+func NewExtractor(llm model.LLM) *Extractor {
+	return &Extractor{
+		Model:    llm,
+		Prompt:   "add this receipt",
+		Confirm:  "yes",
+		MaxTurns: 3,
+	}
+}
+
+// Extract returns the receipt the agent recorded. The transcript holds the
+// agent's text replies, useful when nothing was appended.
+func (e *Extractor) Extract(ctx context.Context, imagePath string) (*Receipt, []string, error) {
+	store := tools.NewMemorySheetStore()
+
+	adkTools, err := tools.NewAdkToolSheets()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create tools: %w", err)
+	}
+
+	trackerAgent, err := agent.NewTrackerAgentWithOptions(ctx, adkTools, agent.Options{
+		Model:       e.Model,
+		SheetStore:  store,
+		Instruction: e.Instruction,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create agent: %w", err)
+	}
+	// Confirmed add_merchant_alias calls must not write to the real alias file
+	tools.SetMerchantDictionary(tools.CurrentMerchantDictionary().InMemoryCopy())
+
+	sessionService := session.InMemoryService()
+	r, err := runner.New(runner.Config{
+		AppName:        "financial_tracker",
+		Agent:          trackerAgent,
+		SessionService: sessionService,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create runner: %w", err)
+	}
+
+	sess, err := sessionService.Create(ctx, &session.CreateRequest{
+		AppName: "financial_tracker",
+		UserID:  "user_eval",
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	msg, err := imageContent(e.Prompt, imagePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var transcript []string
+	for turn := 0; turn <= e.MaxTurns; turn++ {
+		events := r.Run(ctx, "user_eval", sess.Session.ID(), msg, adkagent.RunConfig{
+			StreamingMode: adkagent.StreamingModeNone,
+		})
+		for event, err := range events {
+			if err != nil {
+				return nil, transcript, fmt.Errorf("agent run failed: %w", err)
+			}
+			if event.Content == nil {
+				continue
+			}
+			for _, part := range event.Content.Parts {
+				if part.Text != "" {
+					transcript = append(transcript, part.Text)
+				}
+			}
+		}
+
+		// Approve proposed mutations the way a user pressing Confirm would
+		ref := hitl.SessionRef{AppName: "financial_tracker", UserID: "user_eval", SessionID: sess.Session.ID()}
+		pending, err := hitl.List(ctx, sessionService, ref)
+		if err != nil {
+			return nil, transcript, err
+		}
+		for _, action := range pending {
+			if _, err := hitl.Resolve(ctx, sessionService, ref, action.ID, true); err != nil {
+				return nil, transcript, err
+			}
+		}
+
+		if rows := appendedRows(store); len(rows) > 0 {
+			return receiptFromRows(rows), transcript, nil
+		}
+		msg = genai.NewContentFromText(e.Confirm, genai.RoleUser)
+	}
+
+	return nil, transcript, fmt.Errorf("agent did not append any rows after %d turns", e.MaxTurns+1)
+}
+
+func imageContent(text, imagePath string) (*genai.Content, error) {
+	imageData, err := os.ReadFile(imagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	mimeType := mime.TypeByExtension(filepath.Ext(imagePath))
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	return &genai.Content{
+		Parts: []*genai.Part{
+			genai.NewPartFromText(text),
+			genai.NewPartFromBytes(imageData, mimeType),
+		},
+		Role: genai.RoleUser,
+	}, nil
+}
+
+func appendedRows(store *tools.MemorySheetStore) [][]interface{} {
+	var rows [][]interface{}
+	for _, m := range store.Mutations() {
+		if m.Op == "append" {
+			rows = append(rows, m.Values...)
+		}
+	}
+	return rows
+}
+
+func receiptFromRows(rows [][]interface{}) *Receipt {
+	receipt := &Receipt{}
+	for _, row := range rows {
+		item := Item{
+			Name:   cell(row, tools.ColItemName),
+			Qty:    amountCell(row, tools.ColQty),
+			Amount: amountCell(row, tools.ColAmount),
+		}
+		receipt.Items = append(receipt.Items, item)
+		receipt.Total += item.Amount
+
+		if receipt.Merchant == "" {
+			receipt.Merchant = cell(row, tools.ColMerchant)
+		}
+		if receipt.Date == "" {
+			receipt.Date = parseDate(cell(row, tools.ColReceiptDate))
+		}
+	}
+	return receipt
+}
+
+func cell(row []interface{}, col int) string {
+	if col >= len(row) || row[col] == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprintf("%v", row[col]))
+}
+
+// amountCell reads an amount the way the ledger does; 0 when unreadable.
+func amountCell(row []interface{}, col int) float64 {
+	v, _ := tools.ParseAmount(cell(row, col))
+	return v
+}
+
+func parseDate(s string) string {
+	if len(s) >= 10 && s[4] == '-' && s[7] == '-' {
+		return s[:10]
+	}
+	return ""
+}
+
+// Run extracts every case in order and scores it. Cases run sequentially
+// because the tools share one global sheet store.
+func (e *Extractor) Run(ctx context.Context, cases []Case, progress io.Writer) []Result {
+	results := make([]Result, 0, len(cases))
+
+	for i, c := range cases {
+		fmt.Fprintf(progress, "[%d/%d] %s...\n", i+1, len(cases), c.Name)
+
+		start := time.Now()
+		got, transcript, err := e.Extract(ctx, c.ImagePath)
+
+		res := Result{
+			Case:       c.Name,
+			Expected:   c.Expected,
+			Got:        got,
+			Score:      ScoreReceipt(c.Expected, got),
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+		if err != nil {
+			res.Error = err.Error()
+			res.Transcript = transcript
+		}
+		results = append(results, res)
+	}
+
+	return results
+}
@@ -0,0 +1,132 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// Result is the outcome of one case.
+type Result struct {
+	Case       string   `json:"case"`
+	Expected   Receipt  `json:"expected"`
+	Got        *Receipt `json:"got,omitempty"`
+	Score      Score    `json:"score"`
+	Error      string   `json:"error,omitempty"`
+	Transcript []string `json:"transcript,omitempty"`
+	DurationMs int64    `json:"duration_ms"`
+}
+
+// Summary averages the scores of all cases. Failed runs count as zero.
+type Summary struct {
+	Cases          int     `json:"cases"`
+	Errors         int     `json:"errors"`
+	Merchant       float64 `json:"merchant"`
+	Date           float64 `json:"date"`
+	ItemF1         float64 `json:"itemF1"`
+	AmountAccuracy float64 `json:"amountAccuracy"`
+	Total          float64 `json:"total"`
+}
+
+type Report struct {
+	Model     string    `json:"model"`
+	Prompt    string    `json:"prompt"`
+	StartedAt time.Time `json:"startedAt"`
+	Results   []Result  `json:"results"`
+	Summary   Summary   `json:"summary"`
+}
+
+func (r *Report) Summarize() {
+	s := Summary{Cases: len(r.Results)}
+	dated := 0
+
+	for _, res := range r.Results {
+		if res.Error != "" {
+			s.Errors++
+		}
+		s.Merchant += boolScore(res.Score.Merchant)
+		s.ItemF1 += res.Score.ItemF1
+		s.AmountAccuracy += res.Score.AmountAccuracy
+		s.Total += boolScore(res.Score.Total)
+		if res.Score.DateScored {
+			dated++
+			s.Date += boolScore(res.Score.Date)
+		}
+	}
+
+	if s.Cases > 0 {
+		n := float64(s.Cases)
+		s.Merchant /= n
+		s.ItemF1 /= n
+		s.AmountAccuracy /= n
+		s.Total /= n
+	}
+	if dated > 0 {
+		s.Date /= float64(dated)
+	}
+	r.Summary = s
+}
+
+// Print writes a human readable table of the report.
+func (r *Report) Print(out io.Writer) {
+	fmt.Fprintf(out, "Model:  %s\n", r.Model)
+	fmt.Fprintf(out, "Prompt: %s\n\n", r.Prompt)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CASE\tMERCHANT\tDATE\tITEMS F1\tAMOUNTS\tTOTAL\tTIME")
+	for _, res := range r.Results {
+		date := "-"
+		if res.Score.DateScored {
+			date = mark(res.Score.Date)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.2f\t%.2f\t%s\t%.1fs\n",
+			res.Case,
+			mark(res.Score.Merchant),
+			date,
+			res.Score.ItemF1,
+			res.Score.AmountAccuracy,
+			mark(res.Score.Total),
+			float64(res.DurationMs)/1000,
+		)
+	}
+	w.Flush()
+
+	for _, res := range r.Results {
+		if res.Error != "" {
+			fmt.Fprintf(out, "\n❌ %s: %s\n", res.Case, res.Error)
+		}
+	}
+
+	s := r.Summary
+	fmt.Fprintf(out, "\n📊 %d cases, %d errors\n", s.Cases, s.Errors)
+	fmt.Fprintf(out, "   Merchant: %5.1f%%\n", s.Merchant*100)
+	fmt.Fprintf(out, "   Date:     %5.1f%%\n", s.Date*100)
+	fmt.Fprintf(out, "   Items F1: %5.1f%%\n", s.ItemF1*100)
+	fmt.Fprintf(out, "   Amounts:  %5.1f%%\n", s.AmountAccuracy*100)
+	fmt.Fprintf(out, "   Total:    %5.1f%%\n", s.Total*100)
+}
+
+func (r *Report) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func boolScore(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func mark(b bool) string {
+	if b {
+		return "✓"
+	}
+	return "✗"
+}
@@ -0,0 +1,122 @@
+package eval
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// amountTolerance absorbs rounding in model output (rupiah).
+const amountTolerance = 1.0
+
+// Score compares one extraction with its golden receipt.
+type Score struct {
+	Merchant       bool    `json:"merchant"`
+	Date           bool    `json:"date"`
+	DateScored     bool    `json:"dateScored"` // false when the receipt has no date
+	ItemPrecision  float64 `json:"itemPrecision"`
+	ItemRecall     float64 `json:"itemRecall"`
+	ItemF1         float64 `json:"itemF1"`
+	AmountAccuracy float64 `json:"amountAccuracy"` // expected items with a matching item and amount
+	Total          bool    `json:"total"`
+}
+
+func ScoreReceipt(expected Receipt, got *Receipt) Score {
+	var s Score
+	s.DateScored = expected.Date != ""
+	if got == nil {
+		return s
+	}
+
+	s.Merchant = fuzzyEqual(expected.Merchant, got.Merchant)
+	s.Date = s.DateScored && expected.Date == got.Date
+	s.Total = math.Abs(expected.Total-got.Total) <= amountTolerance
+
+	matches := matchItems(expected.Items, got.Items)
+	if len(got.Items) > 0 {
+		s.ItemPrecision = float64(len(matches)) / float64(len(got.Items))
+	}
+	if len(expected.Items) > 0 {
+		s.ItemRecall = float64(len(matches)) / float64(len(expected.Items))
+
+		amountHits := 0
+		for expIdx, gotIdx := range matches {
+			if math.Abs(expected.Items[expIdx].Amount-got.Items[gotIdx].Amount) <= amountTolerance {
+				amountHits++
+			}
+		}
+		s.AmountAccuracy = float64(amountHits) / float64(len(expected.Items))
+	}
+	if s.ItemPrecision+s.ItemRecall > 0 {
+		s.ItemF1 = 2 * s.ItemPrecision * s.ItemRecall / (s.ItemPrecision + s.ItemRecall)
+	}
+
+	return s
+}
+
+// matchItems pairs expected and extracted items by name, greedily and
+// one-to-one. The result maps expected index to extracted index.
+func matchItems(expected, got []Item) map[int]int {
+	matches := make(map[int]int)
+	used := make(map[int]bool)
+
+	for i, exp := range expected {
+		best, bestScore := -1, 0.0
+		for j, g := range got {
+			if used[j] {
+				continue
+			}
+			if score := nameSimilarity(exp.Name, g.Name); score > bestScore {
+				best, bestScore = j, score
+			}
+		}
+		if best >= 0 && bestScore >= 0.5 {
+			matches[i] = best
+			used[best] = true
+		}
+	}
+	return matches
+}
+
+func fuzzyEqual(a, b string) bool {
+	return nameSimilarity(a, b) >= 0.5
+}
+
+// nameSimilarity is 1 for equal or contained names, otherwise the Jaccard
+// overlap of their words.
+func nameSimilarity(a, b string) float64 {
+	a, b = normalizeName(a), normalizeName(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b || strings.Contains(a, b) || strings.Contains(b, a) {
+		return 1
+	}
+
+	wordsA := strings.Fields(a)
+	setB := make(map[string]bool)
+	for _, w := range strings.Fields(b) {
+		setB[w] = true
+	}
+
+	shared := 0
+	union := len(setB)
+	for _, w := range wordsA {
+		if setB[w] {
+			shared++
+		} else {
+			union++
+		}
+	}
+	return float64(shared) / float64(union)
+}
+
+func normalizeName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,93 @@
+package eval
+
+import "testing"
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"Susu Ultra 1L", "susu ultra 1l", 1},
+		{"Indomaret", "INDOMARET PT INDOMARCO", 1},
+		{"Roti Tawar Sari Roti", "roti tawar", 1},
+		{"Kopi Susu Gula Aren", "Kopi Aren", 0.5},
+		{"Teh Botol", "Air Mineral", 0},
+		{"", "Teh", 0},
+		{"---", "Teh", 0},
+	}
+	for _, tt := range tests {
+		if got := nameSimilarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("nameSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestScoreReceipt(t *testing.T) {
+	expected := Receipt{
+		Merchant: "Indomaret",
+		Date:     "2026-03-14",
+		Items: []Item{
+			{Name: "Susu Ultra 1L", Amount: 18500},
+			{Name: "Roti Tawar", Amount: 15000},
+		},
+		Total: 33500,
+	}
+
+	tests := []struct {
+		name string
+		got  *Receipt
+		want Score
+	}{
+		{
+			name: "nothing extracted",
+			got:  nil,
+			want: Score{DateScored: true},
+		},
+		{
+			name: "exact",
+			got: &Receipt{
+				Merchant: "INDOMARET PT INDOMARCO",
+				Date:     "2026-03-14",
+				Items: []Item{
+					{Name: "roti tawar", Amount: 15000},
+					{Name: "susu ultra 1l", Amount: 18500.4},
+				},
+				Total: 33500.4,
+			},
+			want: Score{Merchant: true, Date: true, DateScored: true, ItemPrecision: 1, ItemRecall: 1, ItemF1: 1, AmountAccuracy: 1, Total: true},
+		},
+		{
+			name: "missed item, wrong amount and extra item",
+			got: &Receipt{
+				Merchant: "Alfamart",
+				Date:     "2026-03-15",
+				Items: []Item{
+					{Name: "Susu Ultra", Amount: 17500},
+					{Name: "Kantong Plastik", Amount: 200},
+				},
+				Total: 17700,
+			},
+			want: Score{DateScored: true, ItemPrecision: 0.5, ItemRecall: 0.5, ItemF1: 0.5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScoreReceipt(expected, tt.got); got != tt.want {
+				t.Errorf("ScoreReceipt() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreReceiptWithoutDate(t *testing.T) {
+	expected := Receipt{Merchant: "Warung Bu Sri", Items: []Item{{Name: "Nasi Campur", Amount: 25000}}, Total: 25000}
+	got := &Receipt{Merchant: "Warung Bu Sri", Date: "2026-03-14", Items: []Item{{Name: "Nasi Campur", Amount: 25000}}, Total: 25000}
+
+	s := ScoreReceipt(expected, got)
+	if s.DateScored || s.Date {
+		t.Errorf("date scored without a golden date: %+v", s)
+	}
+	if !s.Merchant || !s.Total || s.ItemF1 != 1 || s.AmountAccuracy != 1 {
+		t.Errorf("ScoreReceipt() = %+v", s)
+	}
+}
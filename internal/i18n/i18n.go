@@ -0,0 +1,91 @@
+// Package i18n holds the bot texts and the agent language instruction in
+// English and Indonesian.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Lang string
+
+const (
+	English    Lang = "en"
+	Indonesian Lang = "id"
+)
+
+// Default is used when nothing is known about the user; most users write
+// in Indonesian.
+const Default = Indonesian
+
+// StateKey stores the chosen language in user-scoped session state so it is
+// shared by all sessions of the user and visible to the agent.
+const StateKey = "user:language"
+
+// Supported lists the languages in the catalog.
+var Supported = []Lang{English, Indonesian}
+
+// Parse maps a language code or name ("en", "english", "id", "indonesia",
+// "bahasa") to a supported language.
+func Parse(s string) (Lang, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "en", "eng", "english", "inggris":
+		return English, true
+	case "id", "ind", "indonesia", "indonesian", "bahasa":
+		return Indonesian, true
+	}
+	return "", false
+}
+
+// Detect picks a language from a Telegram LanguageCode ("en-US", "id").
+// Unknown or empty codes fall back to Default.
+func Detect(languageCode string) Lang {
+	code, _, _ := strings.Cut(strings.ToLower(languageCode), "-")
+	if lang, ok := Parse(code); ok {
+		return lang
+	}
+	return Default
+}
+
+// FromState returns the language stored under StateKey, if any.
+func FromState(get func(string) (any, error)) (Lang, bool) {
+	v, err := get(StateKey)
+	if err != nil {
+		return "", false
+	}
+	s, _ := v.(string)
+	return Parse(s)
+}
+
+// Name is the language's own name, e.g. for /lang replies.
+func (l Lang) Name() string {
+	if l == English {
+		return "English"
+	}
+	return "Bahasa Indonesia"
+}
+
+// T returns the catalog text for key, formatted with args. Missing
+// translations fall back to English, then to the key itself.
+func T(lang Lang, key string, args ...any) string {
+	text, ok := messages[lang][key]
+	if !ok {
+		text, ok = messages[English][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// Instruction is appended to the system prompt so the agent answers in the
+// user's language. An empty lang tells it to mirror the user.
+func Instruction(lang Lang) string {
+	if lang == "" {
+		return mirrorInstruction
+	}
+	return T(lang, "agent.instruction")
+}
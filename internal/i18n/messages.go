@@ -0,0 +1,383 @@
+package i18n
+
+const mirrorInstruction = `=== LANGUAGE ===
+
+Reply in the same language the user writes in (Indonesian or English).
+Tool arguments, sheet names and column values stay as specified above.`
+
+var messages = map[Lang]map[string]string{
+	English: {
+		"start": `👋 Welcome to AgentAI_Tracker!
+
+I'm an AI-powered financial tracker that can:
+• 📷 Extract transactions from receipt images
+• 📊 Automatically organize data in Google Sheets
+• 🤖 Smart categorization and date handling
+
+Just send me:
+• Text: "Add transaction: Indomaret Rp 50,000"
+• Photo: Receipt image
+• Both: Photo with description
+
+Let's get started! Send me a receipt or transaction details.
+🌐 Bahasa Indonesia? Send /lang id`,
+
+		"help": `🤔 How to use AgentAI_Tracker:
+
+**Text Input:**
+"Indomaret 15 Dec Rp 50,000"
+"Add transaction from Alfamart"
+
+**Image Input:**
+Just send a photo of your receipt
+
+**Combined:**
+Send photo with caption describing the transaction
+
+The bot will:
+1. Extract transaction details
+2. Show you what was found
+3. Ask for confirmation
+4. Save to Google Sheets
+
+📊 /today, /week, /month - spending summary
+🧾 /last [N] - last transactions, /sheets - your sheets
+↩️ /undo - remove the last saved receipt
+📎 /export [today|week|month] - CSV download
+🧹 /reset - start a new conversation
+🗓️ /digest - scheduled spending summaries
+🔔 /bills - recurring bill reminders
+
+🌐 /lang en | /lang id - change language
+🔗 /connect <link> - use your own spreadsheet
+🎟️ /join CODE - join with an invite (owners: /invite, /users, /revoke)
+
+Need help? Just ask me anything!`,
+
+		"lang.current":     "🌐 Language: %s\nChange it with /lang en or /lang id",
+		"lang.changed":     "✅ Language set to %s",
+		"lang.unknown":     "❌ Unknown language '%s'. Use /lang en or /lang id",
+		"lang.failed":      "❌ Failed to change language: %v",
+		"error.photo":      "❌ Failed to download photo: %v",
+		"error.file":       "❌ Failed to download file: %v",
+		"error.audio":      "❌ %v. Send a voice note or an MP3, M4A, OGG or WAV file.",
+		"error.transcribe": "❌ Failed to transcribe the voice note: %v",
+		"error.file_type":  "❌ Can't read '%s'. Send a photo, an image file (JPG, PNG, WEBP) or a PDF receipt.",
+		"error.process":    "❌ Processing error: %v",
+		"error.agent":      "❌ Agent error: %v",
+		"busy":             "⏳ I'm still working on your previous messages, please wait a moment.",
+		"rate.user":        "🚦 You're sending messages faster than I can keep up. Please try again in %d seconds.",
+		"rate.global":      "🚦 I'm busy with other users right now. Please try again in %d seconds.",
+
+		"shutdown.interrupted": "🔄 I'm restarting and couldn't finish this message. Anything already saved stays saved; please send it again in a minute.",
+
+		"access.denied":     "🔒 This bot is private. Ask the owner for an invite code, then send /join CODE",
+		"access.private":    "🔒 Please use this command in a private chat with me.",
+		"access.failed":     "❌ %v",
+		"access.owner_only": "🔒 Only owners can do that.",
+		"notice.denied":     "You are not allowed to do this",
+		"invite.usage":      "Usage: /invite [member|readonly]",
+		"invite.created":    "🎟️ Invite as %s (single use, valid until %[3]s). Forward this:\n\n/join %[2]s",
+		"join.usage":        "Usage: /join CODE",
+		"join.ok":           "✅ Welcome! You joined as %s.",
+		"revoke.usage":      "Usage: /revoke USER_ID",
+		"revoke.ok":         "✅ Access removed for %d",
+		"users.title":       "👥 Users:",
+
+		"connect.usage":   "📊 Spreadsheet: %s\n\nTo use your own, share it with %s as editor, then send /connect <spreadsheet link>",
+		"connect.default": "shared default",
+		"connect.account": "the bot's service account",
+		"connect.ok":      "✅ Connected spreadsheet %s. New transactions from this chat are saved there.",
+		"connect.failed":  "❌ %v\n\nShare the spreadsheet with %s as editor and try again.",
+		"connect.taken":   "❌ That spreadsheet is already connected to another chat. Make a copy or create a new one.",
+		"connect.shared":  "❌ That is the shared default spreadsheet; only an owner can connect it. Send /connect with your own spreadsheet link.",
+
+		"confirm.prompt":    "⏳ %s\n\nSave this to Google Sheets?",
+		"confirm.ok":        "✅ Confirm",
+		"confirm.edit":      "✏️ Edit",
+		"confirm.cancel":    "❌ Cancel",
+		"confirm.saved":     "✅ %s",
+		"confirm.cancelled": "✗ Cancelled, nothing was saved",
+		"confirm.editing":   "✏️ Editing, nothing was saved yet",
+		"confirm.failed":    "❌ Failed: %v",
+		"notice.handled":    "Already handled",
+		"notice.saved":      "Saved",
+		"notice.cancelled":  "Cancelled",
+		"notice.edit":       "Tell me what to change",
+		"notice.failed":     "Failed",
+
+		"cmd.help":    "How to use the bot",
+		"cmd.today":   "Today's spending",
+		"cmd.week":    "Spending this week",
+		"cmd.month":   "Spending this month",
+		"cmd.last":    "Last transactions (/last 10)",
+		"cmd.sheets":  "Sheets in your spreadsheet",
+		"cmd.undo":    "Remove the last saved receipt",
+		"cmd.export":  "Download transactions as CSV",
+		"cmd.reset":   "Start a new conversation",
+		"cmd.digest":  "Scheduled spending summaries",
+		"cmd.bills":   "Recurring bill reminders",
+		"cmd.connect": "Use your own spreadsheet",
+		"cmd.lang":    "Change language",
+
+		"ledger.failed":  "❌ %v",
+		"ledger.empty":   "📭 No transactions yet.",
+		"summary.today":  "Today",
+		"summary.week":   "This week",
+		"summary.month":  "This month",
+		"summary.empty":  "📭 No transactions in this period.",
+		"summary.total":  "Total: **%s** · %d items · %d receipts",
+		"col.category":   "Category",
+		"col.merchant":   "Merchant",
+		"col.amount":     "Amount",
+		"col.sheet":      "Sheet",
+		"col.rows":       "Rows",
+		"col.date":       "Date",
+		"col.item":       "Item",
+		"last.usage":     "Usage: /last [N], N up to %d",
+		"last.title":     "🧾 Last %d transactions",
+		"sheets.title":   "📄 %d sheets",
+		"undo.confirm":   "↩️ Delete %d rows of receipt %s from %s (%s)?",
+		"undo.delete":    "🗑️ Delete",
+		"undo.keep":      "❌ Keep",
+		"undo.kept":      "✗ Kept, nothing was deleted",
+		"undo.ok":        "↩️ Removed %d rows of receipt %s from %s (%s)",
+		"undo.none":      "🤷 No receipt to undo. Only receipts saved since the bot last restarted can be undone; delete older rows in the spreadsheet.",
+		"export.usage":   "Usage: /export [today|week|month]",
+		"export.caption": "📎 %d transactions",
+		"reset.ok":       "🧹 Conversation cleared. Your spreadsheet is unchanged.",
+		"reset.pending":  "⏳ %d confirmation(s) are still pending; their buttons keep working.",
+
+		"digest.daily":   "Daily digest",
+		"digest.weekly":  "Weekly report",
+		"digest.monthly": "Monthly report",
+		"digest.list":    "🗓️ Scheduled summaries:",
+		"digest.none":    "🗓️ No scheduled summaries yet.",
+		"digest.set":     "✅ %s scheduled (%s). Next one: %s",
+		"digest.off":     "🔕 %d scheduled summaries stopped.",
+		"digest.invalid": "❌ %v",
+		"digest.change":  "Previous period: %s (%s)",
+		"digest.usage": `Usage:
+/digest daily 21:00 - today's spending every evening
+/digest weekly mon 08:00 - last week's report
+/digest monthly 1 08:00 - last month's report
+/digest off [daily|weekly|monthly] - stop`,
+
+		"bills.list":     "🔔 Recurring bills:",
+		"bills.none":     "🔔 No recurring bills yet.",
+		"bills.set":      "✅ %s (%s) scheduled %s. First reminder: %s (id %s)",
+		"bills.removed":  "🔕 Bill removed.",
+		"bills.unknown":  "❌ No bill with id '%s'. See /bills",
+		"bills.invalid":  "❌ %v",
+		"bills.reminder": "🔔 Bill due: %s, %s (%s)",
+		"bills.log":      "✅ Log it",
+		"bills.skip":     "⏭️ Skip",
+		"bills.logged":   "✅ Logged to %s",
+		"bills.skipped":  "⏭️ Skipped",
+		"bills.gone":     "⚠️ This bill was removed",
+		"bills.usage": `Usage:
+/bills add <item> | <amount> | <merchant> | <schedule> [| <category>]
+  e.g. /bills add Electricity | 350000 | PLN | monthly 20 09:00
+/bills remove <id> - stop a bill
+Schedules: daily HH:MM, weekly <day> HH:MM, monthly <day> HH:MM`,
+
+		"agent.instruction": `=== LANGUAGE ===
+
+The user's language is English. Always reply in English, including the
+"📋 Extracted from receipt" summary and confirmation requests, even if the
+receipt is in another language.
+Tool arguments, sheet names and column values stay as specified above
+(item names exactly as printed on the receipt).`,
+	},
+
+	Indonesian: {
+		"start": `👋 Selamat datang di AgentAI_Tracker!
+
+Saya asisten keuangan berbasis AI yang bisa:
+• 📷 Membaca transaksi dari foto struk
+• 📊 Menyimpan data otomatis ke Google Sheets
+• 🤖 Mengatur kategori dan tanggal secara cerdas
+
+Kirimkan saja:
+• Teks: "Tambah transaksi: Indomaret Rp 50.000"
+• Foto: Gambar struk belanja
+• Keduanya: Foto dengan keterangan
+
+Yuk mulai! Kirim struk atau detail transaksi kamu.
+🌐 English? Send /lang en`,
+
+		"help": `🤔 Cara memakai AgentAI_Tracker:
+
+**Input Teks:**
+"Indomaret 15 Des Rp 50.000"
+"Tambah transaksi dari Alfamart"
+
+**Input Gambar:**
+Kirim saja foto struk kamu
+
+**Gabungan:**
+Kirim foto dengan keterangan transaksinya
+
+Bot akan:
+1. Membaca detail transaksi
+2. Menampilkan hasil yang ditemukan
+3. Meminta konfirmasi
+4. Menyimpan ke Google Sheets
+
+📊 /today, /week, /month - ringkasan pengeluaran
+🧾 /last [N] - transaksi terakhir, /sheets - daftar sheet
+↩️ /undo - hapus struk terakhir yang disimpan
+📎 /export [today|week|month] - unduh CSV
+🧹 /reset - mulai percakapan baru
+🗓️ /digest - ringkasan pengeluaran terjadwal
+🔔 /bills - pengingat tagihan rutin
+
+🌐 /lang id | /lang en - ganti bahasa
+🔗 /connect <link> - pakai spreadsheet sendiri
+🎟️ /join KODE - gabung dengan undangan (pemilik: /invite, /users, /revoke)
+
+Butuh bantuan? Tanyakan saja!`,
+
+		"lang.current":     "🌐 Bahasa: %s\nGanti dengan /lang id atau /lang en",
+		"lang.changed":     "✅ Bahasa diganti ke %s",
+		"lang.unknown":     "❌ Bahasa '%s' tidak dikenal. Gunakan /lang id atau /lang en",
+		"lang.failed":      "❌ Gagal mengganti bahasa: %v",
+		"error.photo":      "❌ Gagal mengunduh foto: %v",
+		"error.file":       "❌ Gagal mengunduh file: %v",
+		"error.audio":      "❌ %v. Kirim voice note atau file MP3, M4A, OGG atau WAV.",
+		"error.transcribe": "❌ Gagal mentranskripsi voice note: %v",
+		"error.file_type":  "❌ File '%s' tidak bisa dibaca. Kirim foto, file gambar (JPG, PNG, WEBP) atau struk PDF.",
+		"error.process":    "❌ Terjadi kesalahan saat memproses: %v",
+		"error.agent":      "❌ Kesalahan agen: %v",
+		"busy":             "⏳ Pesan sebelumnya masih diproses, mohon tunggu sebentar.",
+		"rate.user":        "🚦 Pesan kamu terlalu cepat. Coba lagi dalam %d detik.",
+		"rate.global":      "🚦 Aku sedang melayani pengguna lain. Coba lagi dalam %d detik.",
+
+		"shutdown.interrupted": "🔄 Bot sedang dimulai ulang dan pesan ini belum selesai diproses. Yang sudah tersimpan tetap aman; kirim ulang sebentar lagi ya.",
+
+		"access.denied":     "🔒 Bot ini privat. Minta kode undangan ke pemilik, lalu kirim /join KODE",
+		"access.private":    "🔒 Gunakan perintah ini di chat pribadi denganku.",
+		"access.failed":     "❌ %v",
+		"access.owner_only": "🔒 Hanya pemilik yang bisa melakukan itu.",
+		"notice.denied":     "Kamu tidak punya akses untuk ini",
+		"invite.usage":      "Cara pakai: /invite [member|readonly]",
+		"invite.created":    "🎟️ Undangan sebagai %s (sekali pakai, berlaku sampai %[3]s). Teruskan pesan ini:\n\n/join %[2]s",
+		"join.usage":        "Cara pakai: /join KODE",
+		"join.ok":           "✅ Selamat datang! Kamu bergabung sebagai %s.",
+		"revoke.usage":      "Cara pakai: /revoke USER_ID",
+		"revoke.ok":         "✅ Akses %d sudah dicabut",
+		"users.title":       "👥 Pengguna:",
+
+		"connect.usage":   "📊 Spreadsheet: %s\n\nUntuk memakai milikmu sendiri, bagikan ke %s sebagai editor, lalu kirim /connect <link spreadsheet>",
+		"connect.default": "default bersama",
+		"connect.account": "service account bot",
+		"connect.ok":      "✅ Spreadsheet %s tersambung. Transaksi baru dari chat ini disimpan di sana.",
+		"connect.failed":  "❌ %v\n\nBagikan spreadsheet ke %s sebagai editor lalu coba lagi.",
+		"connect.taken":   "❌ Spreadsheet itu sudah tersambung ke chat lain. Buat salinan atau spreadsheet baru.",
+		"connect.shared":  "❌ Itu spreadsheet default bersama; hanya owner yang bisa menyambungkannya. Kirim /connect dengan link spreadsheet milikmu.",
+
+		"confirm.prompt":    "⏳ %s\n\nSimpan ke Google Sheets?",
+		"confirm.ok":        "✅ Simpan",
+		"confirm.edit":      "✏️ Ubah",
+		"confirm.cancel":    "❌ Batal",
+		"confirm.saved":     "✅ %s",
+		"confirm.cancelled": "✗ Dibatalkan, tidak ada yang disimpan",
+		"confirm.editing":   "✏️ Sedang diubah, belum ada yang disimpan",
+		"confirm.failed":    "❌ Gagal: %v",
+		"notice.handled":    "Sudah diproses",
+		"notice.saved":      "Tersimpan",
+		"notice.cancelled":  "Dibatalkan",
+		"notice.edit":       "Kirim bagian yang perlu diubah",
+		"notice.failed":     "Gagal",
+
+		"cmd.help":    "Cara memakai bot",
+		"cmd.today":   "Pengeluaran hari ini",
+		"cmd.week":    "Pengeluaran minggu ini",
+		"cmd.month":   "Pengeluaran bulan ini",
+		"cmd.last":    "Transaksi terakhir (/last 10)",
+		"cmd.sheets":  "Daftar sheet di spreadsheet",
+		"cmd.undo":    "Hapus struk terakhir yang disimpan",
+		"cmd.export":  "Unduh transaksi sebagai CSV",
+		"cmd.reset":   "Mulai percakapan baru",
+		"cmd.digest":  "Ringkasan pengeluaran terjadwal",
+		"cmd.bills":   "Pengingat tagihan rutin",
+		"cmd.connect": "Pakai spreadsheet sendiri",
+		"cmd.lang":    "Ganti bahasa",
+
+		"ledger.failed":  "❌ %v",
+		"ledger.empty":   "📭 Belum ada transaksi.",
+		"summary.today":  "Hari ini",
+		"summary.week":   "Minggu ini",
+		"summary.month":  "Bulan ini",
+		"summary.empty":  "📭 Tidak ada transaksi di periode ini.",
+		"summary.total":  "Total: **%s** · %d barang · %d struk",
+		"col.category":   "Kategori",
+		"col.merchant":   "Toko",
+		"col.amount":     "Jumlah",
+		"col.sheet":      "Sheet",
+		"col.rows":       "Baris",
+		"col.date":       "Tgl",
+		"col.item":       "Barang",
+		"last.usage":     "Cara pakai: /last [N], N maksimal %d",
+		"last.title":     "🧾 %d transaksi terakhir",
+		"sheets.title":   "📄 %d sheet",
+		"undo.confirm":   "↩️ Hapus %d baris struk %s dari %s (%s)?",
+		"undo.delete":    "🗑️ Hapus",
+		"undo.keep":      "❌ Simpan",
+		"undo.kept":      "✗ Tidak jadi, tidak ada yang dihapus",
+		"undo.ok":        "↩️ %d baris struk %s dihapus dari %s (%s)",
+		"undo.none":      "🤷 Tidak ada struk untuk dibatalkan. Hanya struk yang disimpan sejak bot terakhir dimulai ulang yang bisa dibatalkan; hapus baris lama langsung di spreadsheet.",
+		"export.usage":   "Cara pakai: /export [today|week|month]",
+		"export.caption": "📎 %d transaksi",
+		"reset.ok":       "🧹 Percakapan dihapus. Spreadsheet kamu tidak berubah.",
+		"reset.pending":  "⏳ %d konfirmasi masih menunggu; tombolnya tetap bisa dipakai.",
+
+		"digest.daily":   "Ringkasan harian",
+		"digest.weekly":  "Laporan mingguan",
+		"digest.monthly": "Laporan bulanan",
+		"digest.list":    "🗓️ Ringkasan terjadwal:",
+		"digest.none":    "🗓️ Belum ada ringkasan terjadwal.",
+		"digest.set":     "✅ %s dijadwalkan (%s). Berikutnya: %s",
+		"digest.off":     "🔕 %d ringkasan terjadwal dihentikan.",
+		"digest.invalid": "❌ %v",
+		"digest.change":  "Periode sebelumnya: %s (%s)",
+		"digest.usage": `Cara pakai:
+/digest daily 21:00 - pengeluaran hari ini setiap malam
+/digest weekly senin 08:00 - laporan minggu lalu
+/digest monthly 1 08:00 - laporan bulan lalu
+/digest off [daily|weekly|monthly] - hentikan`,
+
+		"bills.list":     "🔔 Tagihan rutin:",
+		"bills.none":     "🔔 Belum ada tagihan rutin.",
+		"bills.set":      "✅ %s (%s) dijadwalkan %s. Pengingat pertama: %s (id %s)",
+		"bills.removed":  "🔕 Tagihan dihapus.",
+		"bills.unknown":  "❌ Tidak ada tagihan dengan id '%s'. Lihat /bills",
+		"bills.invalid":  "❌ %v",
+		"bills.reminder": "🔔 Tagihan jatuh tempo: %s, %s (%s)",
+		"bills.log":      "✅ Catat",
+		"bills.skip":     "⏭️ Lewati",
+		"bills.logged":   "✅ Dicatat di %s",
+		"bills.skipped":  "⏭️ Dilewati",
+		"bills.gone":     "⚠️ Tagihan ini sudah dihapus",
+		"bills.usage": `Cara pakai:
+/bills add <item> | <jumlah> | <merchant> | <jadwal> [| <kategori>]
+  contoh: /bills add Listrik | 350000 | PLN | monthly 20 09:00
+/bills remove <id> - hentikan tagihan
+Jadwal: daily HH:MM, weekly <hari> HH:MM, monthly <tanggal> HH:MM`,
+
+		"agent.instruction": `=== BAHASA ===
+
+Bahasa pengguna adalah Bahasa Indonesia. Selalu balas dalam Bahasa Indonesia
+yang santai dan jelas, termasuk ringkasan struk dan permintaan konfirmasi.
+Gunakan label ringkasan berikut:
+"📋 Hasil dari struk:
+ Toko: [merchant_name]
+ Tanggal Struk: [YYYY-MM-DD]
+ Barang:
+ - [item_name] x[qty] @ Rp[unit_price] = Rp[amount]
+ Total: Rp[total]
+ ID Struk: [generated_id]"
+Argumen tool, nama sheet, kategori dan nilai kolom tetap mengikuti aturan di
+atas (kategori dalam bahasa Inggris, nama barang sesuai yang tertulis di struk).`,
+	},
+}
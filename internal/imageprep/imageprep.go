@@ -0,0 +1,207 @@
+// Package imageprep prepares receipt images before they are sent to the
+// model: the real type is sniffed, EXIF orientation applied, large photos
+// downscaled and re-encoded as JPEG, optionally in high-contrast grayscale.
+// Phone photos shrink from several MB to a few hundred KB, which cuts
+// upload time and tokens without losing legible text.
+package imageprep
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	_ "image/gif"
+	_ "image/png"
+)
+
+// Options controls the preprocessing. The zero value only sniffs the type
+// and applies the orientation.
+type Options struct {
+	// MaxDimension caps the longest side in pixels; 0 keeps the size.
+	MaxDimension int
+	// Quality is the JPEG quality (1-100) of re-encoded images.
+	Quality int
+	// Grayscale drops color, which receipts rarely need.
+	Grayscale bool
+	// Contrast stretches the gray levels so faded thermal prints become
+	// darker. It implies Grayscale.
+	Contrast bool
+}
+
+// DefaultOptions keeps long receipts legible while dropping most of the
+// bytes of a phone photo.
+func DefaultOptions() Options {
+	return Options{MaxDimension: 2048, Quality: 85}
+}
+
+// OptionsFromEnv returns DefaultOptions overridden by IMAGE_MAX_DIMENSION,
+// IMAGE_JPEG_QUALITY, IMAGE_GRAYSCALE and IMAGE_CONTRAST. Invalid values
+// are ignored with a warning.
+func OptionsFromEnv() Options {
+	opts := DefaultOptions()
+	if v := os.Getenv("IMAGE_MAX_DIMENSION"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			opts.MaxDimension = n
+		} else {
+			log.Printf("⚠️ Ignoring IMAGE_MAX_DIMENSION=%q", v)
+		}
+	}
+	if v := os.Getenv("IMAGE_JPEG_QUALITY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 1 && n <= 100 {
+			opts.Quality = n
+		} else {
+			log.Printf("⚠️ Ignoring IMAGE_JPEG_QUALITY=%q", v)
+		}
+	}
+	for name, dst := range map[string]*bool{"IMAGE_GRAYSCALE": &opts.Grayscale, "IMAGE_CONTRAST": &opts.Contrast} {
+		if v := os.Getenv(name); v != "" {
+			if b, err := strconv.ParseBool(v); err == nil {
+				*dst = b
+			} else {
+				log.Printf("⚠️ Ignoring %s=%q", name, v)
+			}
+		}
+	}
+	return opts
+}
+
+// maxPixels guards against decompression bombs; larger images are sent
+// as they are.
+const maxPixels = 50_000_000
+
+// Image is a prepared image.
+type Image struct {
+	Data     []byte
+	MimeType string
+	// Width and Height are the final size; 0 when the data was passed
+	// through without decoding (PDFs, WebP).
+	Width, Height int
+	// Original is the size of the input in bytes.
+	Original int
+}
+
+// Sniff returns the MIME type of data from its content, not its name.
+func Sniff(data []byte) string {
+	return http.DetectContentType(data)
+}
+
+// SniffFile returns the MIME type of the file at path from its first
+// 512 bytes.
+func SniffFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, _ := f.Read(head)
+	return Sniff(head[:n]), nil
+}
+
+// PrepareFile reads and prepares the image at path.
+func PrepareFile(path string, opts Options) (*Image, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	return Prepare(data, opts)
+}
+
+// Prepare processes data according to opts. Types Go cannot decode (PDF,
+// WebP, ...) are returned unchanged with their sniffed type, as is an
+// image that would not get smaller without any other change.
+func Prepare(data []byte, opts Options) (*Image, error) {
+	mimeType := Sniff(data)
+	passThrough := &Image{Data: data, MimeType: mimeType, Original: len(data)}
+
+	switch mimeType {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		return passThrough, nil
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", mimeType, err)
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		log.Printf("⚠️ Image too large to process (%dx%d), sending as is", cfg.Width, cfg.Height)
+		return passThrough, nil
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", mimeType, err)
+	}
+
+	orientation := 1
+	if mimeType == "image/jpeg" {
+		orientation = exifOrientation(data)
+	}
+
+	img := toRGBA(src)
+	resized := false
+	if opts.MaxDimension > 0 {
+		if b := img.Bounds(); max(b.Dx(), b.Dy()) > opts.MaxDimension {
+			img = downscale(img, opts.MaxDimension)
+			resized = true
+		}
+	}
+	img = orient(img, orientation)
+
+	var out image.Image = img
+	if opts.Grayscale || opts.Contrast {
+		gray := toGray(img)
+		if opts.Contrast {
+			stretch(gray)
+		}
+		out = gray
+	}
+
+	quality := opts.Quality
+	if quality <= 0 {
+		quality = jpeg.DefaultQuality
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, out, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	// Nothing but the encoding would change; keep the smaller original
+	unchanged := !resized && orientation == 1 && !opts.Grayscale && !opts.Contrast
+	if unchanged && buf.Len() >= len(data) {
+		b := src.Bounds()
+		passThrough.Width, passThrough.Height = b.Dx(), b.Dy()
+		return passThrough, nil
+	}
+
+	b := out.Bounds()
+	return &Image{
+		Data:     buf.Bytes(),
+		MimeType: "image/jpeg",
+		Width:    b.Dx(),
+		Height:   b.Dy(),
+		Original: len(data),
+	}, nil
+}
+
+// toRGBA copies src into an RGBA image at the origin. Transparent areas
+// (screenshots) become white, since JPEG has no alpha.
+func toRGBA(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	if o, ok := src.(interface{ Opaque() bool }); ok && o.Opaque() {
+		draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+		return dst
+	}
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+	return dst
+}
@@ -0,0 +1,251 @@
+package imageprep
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"math/rand"
+	"testing"
+)
+
+// noise returns a w×h image of random pixels, which compresses badly like
+// a photo does.
+func noise(w, h int) *image.RGBA {
+	rng := rand.New(rand.NewSource(1))
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	rng.Read(img.Pix)
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 0xFF
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image, quality int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// tiff builds a TIFF header whose IFD0 holds only the orientation tag.
+func tiff(order binary.ByteOrder, orientation uint16) []byte {
+	b := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(b, "II")
+	} else {
+		copy(b, "MM")
+	}
+	order.PutUint16(b[2:], 42)
+	order.PutUint32(b[4:], 8)
+	order.PutUint16(b[8:], 1)
+	order.PutUint16(b[10:], 0x0112) // tag
+	order.PutUint16(b[12:], 3)      // SHORT
+	order.PutUint32(b[14:], 1)      // count
+	order.PutUint16(b[18:], orientation)
+	return b
+}
+
+// withAPP1 inserts an APP1 segment holding payload right after the SOI
+// marker of jpegData.
+func withAPP1(jpegData, payload []byte) []byte {
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, jpegData[:2]...)
+	out = append(out, segment...)
+	return append(out, jpegData[2:]...)
+}
+
+func withExif(jpegData, tiffData []byte) []byte {
+	return withAPP1(jpegData, append([]byte("Exif\x00\x00"), tiffData...))
+}
+
+func TestExifOrientation(t *testing.T) {
+	plain := encodeJPEG(t, noise(8, 8), 90)
+
+	badOffset := tiff(binary.BigEndian, 6)
+	binary.BigEndian.PutUint32(badOffset[4:], 1<<20)
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"no exif", plain, 1},
+		{"big-endian", withExif(plain, tiff(binary.BigEndian, 6)), 6},
+		{"little-endian", withExif(plain, tiff(binary.LittleEndian, 8)), 8},
+		{"little-endian upside down", withExif(plain, tiff(binary.LittleEndian, 3)), 3},
+		{"out of range value", withExif(plain, tiff(binary.BigEndian, 9)), 1},
+		{"IFD offset past the end", withExif(plain, badOffset), 1},
+		{"short TIFF header", withExif(plain, []byte("MM\x00")), 1},
+		{"unknown byte order", withExif(plain, append([]byte("XX"), tiff(binary.BigEndian, 6)[2:]...)), 1},
+		{"APP1 that is not EXIF", withAPP1(plain, []byte("http://ns.adobe.com/xap/1.0/\x00")), 1},
+		{"not a JPEG", []byte("GIF89a"), 1},
+		{"empty", nil, 1},
+	}
+	for _, tt := range tests {
+		if got := exifOrientation(tt.data); got != tt.want {
+			t.Errorf("%s: orientation = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExifOrientationTruncated(t *testing.T) {
+	data := withExif(encodeJPEG(t, noise(8, 8), 90), tiff(binary.LittleEndian, 6))
+	app1End := 2 + 2 + int(binary.BigEndian.Uint16(data[4:]))
+
+	// Every cut inside the APP1 segment must read as "no orientation"
+	for n := 0; n < app1End; n++ {
+		if got := exifOrientation(data[:n]); got != 1 {
+			t.Fatalf("truncated to %d bytes: orientation = %d, want 1", n, got)
+		}
+	}
+
+	// A segment length that runs past the data or is too small to hold
+	// its own length field
+	for _, size := range []uint16{0, 1, 0xFFFF} {
+		corrupt := append([]byte{}, data...)
+		binary.BigEndian.PutUint16(corrupt[4:], size)
+		if got := exifOrientation(corrupt); got != 1 {
+			t.Errorf("APP1 size %d: orientation = %d, want 1", size, got)
+		}
+	}
+
+	// An IFD entry count larger than the data
+	corrupt := append([]byte{}, data...)
+	binary.LittleEndian.PutUint16(corrupt[2+4+6+8:], 0xFFFF)
+	binary.LittleEndian.PutUint16(corrupt[2+4+6+10:], 0x0100) // not the orientation tag
+	if got := exifOrientation(corrupt); got != 1 {
+		t.Errorf("huge IFD count: orientation = %d, want 1", got)
+	}
+}
+
+func TestPrepareAppliesOrientation(t *testing.T) {
+	data := withExif(encodeJPEG(t, noise(40, 20), 90), tiff(binary.BigEndian, 6))
+
+	img, err := Prepare(data, DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Width != 20 || img.Height != 40 {
+		t.Errorf("size = %dx%d, want 20x40 after turning 90°", img.Width, img.Height)
+	}
+}
+
+func TestPrepareDownscalesLongestSide(t *testing.T) {
+	tests := []struct {
+		w, h, limit  int
+		wantW, wantH int
+	}{
+		{300, 100, 100, 100, 33},
+		{100, 300, 100, 33, 100},
+		{120, 120, 60, 60, 60},
+	}
+	for _, tt := range tests {
+		data := encodeJPEG(t, noise(tt.w, tt.h), 90)
+		img, err := Prepare(data, Options{MaxDimension: tt.limit, Quality: 85})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if img.Width != tt.wantW || img.Height != tt.wantH {
+			t.Errorf("%dx%d limit %d: size = %dx%d, want %dx%d", tt.w, tt.h, tt.limit, img.Width, img.Height, tt.wantW, tt.wantH)
+		}
+		if cfg, err := jpeg.DecodeConfig(bytes.NewReader(img.Data)); err != nil || cfg.Width != tt.wantW || cfg.Height != tt.wantH {
+			t.Errorf("%dx%d limit %d: encoded %dx%d (err %v)", tt.w, tt.h, tt.limit, cfg.Width, cfg.Height, err)
+		}
+		if img.Original != len(data) {
+			t.Errorf("Original = %d, want %d", img.Original, len(data))
+		}
+	}
+}
+
+func TestPreparePassesThrough(t *testing.T) {
+	pdf := []byte("%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\n%%EOF\n")
+	webp := append([]byte("RIFF\x24\x00\x00\x00WEBPVP8 "), make([]byte, 24)...)
+	// Re-encoding at a higher quality only makes it bigger
+	small := encodeJPEG(t, noise(64, 64), 30)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		opts     Options
+		wantMime string
+		wantW    int
+	}{
+		{"pdf", pdf, DefaultOptions(), "application/pdf", 0},
+		{"webp", webp, DefaultOptions(), "image/webp", 0},
+		{"jpeg that would not shrink", small, Options{MaxDimension: 2048, Quality: 100}, "image/jpeg", 64},
+	}
+	for _, tt := range tests {
+		img, err := Prepare(tt.data, tt.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !bytes.Equal(img.Data, tt.data) {
+			t.Errorf("%s: data changed", tt.name)
+		}
+		if img.MimeType != tt.wantMime || img.Width != tt.wantW {
+			t.Errorf("%s: got %s %dpx wide, want %s %dpx", tt.name, img.MimeType, img.Width, tt.wantMime, tt.wantW)
+		}
+	}
+}
+
+func TestPrepareTransparentBecomesWhite(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 128, 128)) // fully transparent
+	for y := 0; y < 128; y++ {
+		for x := 64; x < 128; x++ {
+			src.Set(x, y, color.NRGBA{A: 0xFF}) // right half opaque black
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := Prepare(buf.Bytes(), Options{MaxDimension: 64, Quality: 90})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.MimeType != "image/jpeg" {
+		t.Fatalf("MimeType = %s, want image/jpeg", img.MimeType)
+	}
+	out, err := jpeg.Decode(bytes.NewReader(img.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, g, b, _ := out.At(8, 32).RGBA()
+	if r>>8 < 240 || g>>8 < 240 || b>>8 < 240 {
+		t.Errorf("transparent pixel = (%d,%d,%d), want white", r>>8, g>>8, b>>8)
+	}
+	r, g, b, _ = out.At(56, 32).RGBA()
+	if r>>8 > 15 || g>>8 > 15 || b>>8 > 15 {
+		t.Errorf("opaque black pixel = (%d,%d,%d), want black", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestPrepareTooManyPixels(t *testing.T) {
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, image.NewPaletted(image.Rect(0, 0, 1, 1), color.Palette{color.Black}), nil); err != nil {
+		t.Fatal(err)
+	}
+	// Claim a 10000×10000 logical screen; only the header is read
+	data := buf.Bytes()
+	binary.LittleEndian.PutUint16(data[6:], 10000)
+	binary.LittleEndian.PutUint16(data[8:], 10000)
+
+	img, err := Prepare(data, DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(img.Data, data) || img.MimeType != "image/gif" || img.Width != 0 {
+		t.Errorf("got %s %dx%d, want the GIF passed through undecoded", img.MimeType, img.Width, img.Height)
+	}
+}
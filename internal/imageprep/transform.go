@@ -0,0 +1,175 @@
+package imageprep
+
+import (
+	"encoding/binary"
+	"image"
+)
+
+// exifOrientation reads the EXIF orientation (1-8) of a JPEG; 1 (as
+// stored) when there is none.
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xFF {
+		marker := data[pos+1]
+		size := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if marker == 0xDA || size < 2 || pos+2+size > len(data) {
+			break // image data starts; EXIF comes before it
+		}
+		segment := data[pos+4 : pos+2+size]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(segment[6:])
+		}
+		pos += 2 + size
+	}
+	return 1
+}
+
+// tiffOrientation finds tag 0x0112 in IFD0 of a TIFF header.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			break
+		}
+	}
+	return 1
+}
+
+// orient turns an image stored with EXIF orientation o upright.
+func orient(src *image.RGBA, o int) *image.RGBA {
+	if o <= 1 || o > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if o >= 5 {
+		dw, dh = h, w // 90° turns swap the sides
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch o {
+			case 2: // mirrored
+				sx, sy = w-1-x, y
+			case 3: // upside down
+				sx, sy = w-1-x, h-1-y
+			case 4: // flipped
+				sx, sy = x, h-1-y
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // needs 90° clockwise
+				sx, sy = y, h-1-x
+			case 7: // transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // needs 90° counter-clockwise
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):][:4], src.Pix[src.PixOffset(sx, sy):][:4])
+		}
+	}
+	return dst
+}
+
+// downscale shrinks src so its longest side is limit, averaging the
+// source pixels under each destination pixel, which keeps thin strokes
+// of text readable.
+func downscale(src *image.RGBA, limit int) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := limit, max(h*limit/w, 1)
+	if h > w {
+		dw, dh = max(w*limit/h, 1), limit
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, max((y+1)*h/dh, y*h/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, max((x+1)*w/dw, x*w/dw+1)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[src.PixOffset(x0, sy):]
+				for i := 0; i < (x1-x0)*4; i++ {
+					sum[i%4] += int(row[i])
+				}
+			}
+			n := (x1 - x0) * (y1 - y0)
+			px := dst.Pix[dst.PixOffset(x, y):]
+			for c := range sum {
+				px[c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
+
+// toGray converts to luminance (ITU-R BT.601, like image/color).
+func toGray(src *image.RGBA) *image.Gray {
+	b := src.Bounds()
+	dst := image.NewGray(b)
+	for i, j := 0, 0; i < len(src.Pix); i, j = i+4, j+1 {
+		r, g, bl := int(src.Pix[i]), int(src.Pix[i+1]), int(src.Pix[i+2])
+		dst.Pix[j] = uint8((299*r + 587*g + 114*bl + 500) / 1000)
+	}
+	return dst
+}
+
+// stretch maps the gray levels so the darkest 1% becomes black and the
+// lightest 1% white.
+func stretch(img *image.Gray) {
+	var hist [256]int
+	for _, v := range img.Pix {
+		hist[v]++
+	}
+
+	clip := len(img.Pix) / 100
+	lo, hi := 0, 255
+	for n := 0; lo < 255 && n+hist[lo] <= clip; lo++ {
+		n += hist[lo]
+	}
+	for n := 0; hi > 0 && n+hist[hi] <= clip; hi-- {
+		n += hist[hi]
+	}
+	if hi <= lo {
+		return // flat image
+	}
+
+	var lut [256]uint8
+	for v := range lut {
+		lut[v] = uint8(min(max((v-lo)*255/(hi-lo), 0), 255))
+	}
+	for i, v := range img.Pix {
+		img.Pix[i] = lut[v]
+	}
+}
@@ -0,0 +1,67 @@
+// Package replay records agent conversations to fixture files and replays
+// them without calling the live model or the Sheets API.
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// Fixture is everything needed to replay one conversation: the user
+// messages in order, every model call with its responses, and the tool
+// results observed during recording.
+type Fixture struct {
+	Name         string           `json:"name"`
+	Model        string           `json:"model,omitempty"`
+	RecordedAt   time.Time        `json:"recordedAt"`
+	UserMessages []*genai.Content `json:"userMessages"`
+	ModelCalls   []ModelCall      `json:"modelCalls"`
+	ToolCalls    []ToolCall       `json:"toolCalls"`
+}
+
+// ModelCall is one GenerateContent call. Request holds only the newest
+// content sent to the model to keep fixtures small.
+type ModelCall struct {
+	Request   *genai.Content       `json:"request,omitempty"`
+	Responses []*model.LLMResponse `json:"responses"`
+	Error     string               `json:"error,omitempty"`
+}
+
+// ToolCall is one tool execution and its result.
+type ToolCall struct {
+	Name   string         `json:"name"`
+	Args   map[string]any `json:"args,omitempty"`
+	Result map[string]any `json:"result,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+func (f *Fixture) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixture: %w", err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
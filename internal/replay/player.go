@@ -0,0 +1,287 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+	"time"
+
+	"finagent/internal/agent"
+	"finagent/internal/agent/hitl"
+	"finagent/internal/agent/tools"
+
+	adkagent "google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+)
+
+// readOnlyTools are served from the fixture during replay. Every other tool
+// runs for real against the player's MemorySheetStore.
+var readOnlyTools = map[string]bool{
+	"list_sheets":     true,
+	"read_from_sheet": true,
+}
+
+// ReplayModel serves recorded model responses in order.
+type ReplayModel struct {
+	name  string
+	calls []ModelCall
+	mu    sync.Mutex
+	next  int
+}
+
+func NewReplayModel(f *Fixture) *ReplayModel {
+	return &ReplayModel{name: f.Model, calls: f.ModelCalls}
+}
+
+func (m *ReplayModel) Name() string {
+	return m.name
+}
+
+func (m *ReplayModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.mu.Lock()
+		idx := m.next
+		m.next++
+		m.mu.Unlock()
+
+		if idx >= len(m.calls) {
+			yield(nil, fmt.Errorf("replay: no recorded response for model call %d", idx+1))
+			return
+		}
+
+		call := m.calls[idx]
+		for _, resp := range call.Responses {
+			if !yield(resp, nil) {
+				return
+			}
+		}
+		if call.Error != "" {
+			yield(nil, errors.New(call.Error))
+		}
+	}
+}
+
+// Remaining returns how many recorded model calls have not been served.
+func (m *ReplayModel) Remaining() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return max(len(m.calls)-m.next, 0)
+}
+
+// Player replays a Fixture through a real runner.Runner. The model is a
+// ReplayModel, read-only tools return their recorded results and mutating
+// tools write into Store, so callers can assert on the resulting sheets.
+// Pending confirmations are approved after every turn.
+type Player struct {
+	Fixture *Fixture
+	Store   *tools.MemorySheetStore
+	Runner  *runner.Runner
+
+	model          *ReplayModel
+	sessionService session.Service
+	mu             sync.Mutex
+	toolCalls      map[string][]ToolCall
+}
+
+// PlayerOptions configures a Player. Category rules and merchant aliases
+// default to the files the agent loads, which are relative to the
+// repository root; tests running in a package directory pass their own.
+type PlayerOptions struct {
+	// Store receives the mutations. If nil an empty one is created and
+	// seeded from the first recorded list_sheets result.
+	Store         *tools.MemorySheetStore
+	CategoryRules []tools.CategoryRule
+	// Merchants is copied, so add_merchant_alias never writes to its file.
+	Merchants *tools.MerchantDictionary
+}
+
+// NewPlayer builds the tracker agent around the fixture. If store is nil an
+// empty one is created and seeded from the first recorded list_sheets result.
+func NewPlayer(ctx context.Context, f *Fixture, store *tools.MemorySheetStore) (*Player, error) {
+	return NewPlayerWithOptions(ctx, f, PlayerOptions{Store: store})
+}
+
+// NewPlayerWithOptions is NewPlayer with explicit rules and aliases.
+func NewPlayerWithOptions(ctx context.Context, f *Fixture, opts PlayerOptions) (*Player, error) {
+	store := opts.Store
+	if store == nil {
+		store = tools.NewMemorySheetStore()
+		seedFromFixture(store, f)
+	}
+
+	p := &Player{
+		Fixture:        f,
+		Store:          store,
+		model:          NewReplayModel(f),
+		sessionService: session.InMemoryService(),
+		toolCalls:      make(map[string][]ToolCall),
+	}
+	for _, call := range f.ToolCalls {
+		if readOnlyTools[call.Name] {
+			p.toolCalls[call.Name] = append(p.toolCalls[call.Name], call)
+		}
+	}
+
+	adkTools, err := tools.NewAdkToolSheets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tools: %w", err)
+	}
+
+	var merchants *tools.MerchantDictionary
+	if opts.Merchants != nil {
+		merchants = opts.Merchants.InMemoryCopy()
+	}
+
+	trackerAgent, err := agent.NewTrackerAgentWithOptions(ctx, adkTools, agent.Options{
+		Model:               p.model,
+		SheetStore:          store,
+		CategoryRules:       opts.CategoryRules,
+		Merchants:           merchants,
+		BeforeToolCallbacks: []llmagent.BeforeToolCallback{p.serveRecorded},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent: %w", err)
+	}
+	if merchants == nil {
+		// add_merchant_alias must not write to the real alias file
+		tools.SetMerchantDictionary(tools.CurrentMerchantDictionary().InMemoryCopy())
+	}
+
+	p.Runner, err = runner.New(runner.Config{
+		AppName:        "financial_tracker",
+		Agent:          trackerAgent,
+		SessionService: p.sessionService,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create runner: %w", err)
+	}
+
+	return p, nil
+}
+
+// Play sends every recorded user message through the runner and returns
+// the produced events. It fails if the run errors or if recorded model
+// calls are left over, which means the conversation diverged.
+func (p *Player) Play(ctx context.Context) ([]*session.Event, error) {
+	tools.SetClock(func() time.Time { return p.Fixture.RecordedAt })
+	defer tools.SetClock(nil)
+
+	sess, err := p.sessionService.Create(ctx, &session.CreateRequest{
+		AppName: "financial_tracker",
+		UserID:  "user_replay",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	var events []*session.Event
+	for i, msg := range p.Fixture.UserMessages {
+		run := p.Runner.Run(ctx, "user_replay", sess.Session.ID(), msg, adkagent.RunConfig{
+			StreamingMode: adkagent.StreamingModeNone,
+		})
+		for event, err := range run {
+			if err != nil {
+				return events, fmt.Errorf("replay turn %d: %w", i+1, err)
+			}
+			events = append(events, event)
+		}
+
+		if err := p.confirmPending(ctx, sess.Session.ID()); err != nil {
+			return events, fmt.Errorf("replay turn %d: %w", i+1, err)
+		}
+	}
+
+	if left := p.model.Remaining(); left > 0 {
+		return events, fmt.Errorf("replay finished with %d unused model calls", left)
+	}
+	return events, nil
+}
+
+// confirmPending approves every pending mutation, standing in for the user
+// pressing Confirm. Sessions where the user cancelled a mutation are not
+// reproduced faithfully.
+func (p *Player) confirmPending(ctx context.Context, sessionID string) error {
+	ref := hitl.SessionRef{
+		AppName:   "financial_tracker",
+		UserID:    "user_replay",
+		SessionID: sessionID,
+	}
+
+	pending, err := hitl.List(ctx, p.sessionService, ref)
+	if err != nil {
+		return err
+	}
+	for _, action := range pending {
+		if _, err := hitl.Resolve(ctx, p.sessionService, ref, action.ID, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Player) serveRecorded(ctx tool.Context, t tool.Tool, args map[string]any) (map[string]any, error) {
+	if !readOnlyTools[t.Name()] {
+		return nil, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	queue := p.toolCalls[t.Name()]
+	if len(queue) == 0 {
+		// Nothing recorded, fall through to the memory store
+		return nil, nil
+	}
+	call := queue[0]
+	p.toolCalls[t.Name()] = queue[1:]
+
+	if call.Error != "" {
+		return nil, errors.New(call.Error)
+	}
+	return call.Result, nil
+}
+
+// seedFromFixture recreates the sheets that existed before recording, as
+// reported by the first list_sheets call. Non-empty sheets get a header row.
+func seedFromFixture(store *tools.MemorySheetStore, f *Fixture) {
+	for _, call := range f.ToolCalls {
+		if call.Name == "create_new_sheet" {
+			return
+		}
+		if call.Name != "list_sheets" {
+			continue
+		}
+
+		sheets, _ := call.Result["sheets"].([]any)
+		for _, s := range sheets {
+			info, ok := s.(map[string]any)
+			if !ok {
+				continue
+			}
+			title, _ := info["title"].(string)
+			if title == "" {
+				continue
+			}
+			var rows [][]interface{}
+			if isEmpty, _ := info["isEmpty"].(bool); !isEmpty {
+				rows = [][]interface{}{toRow(tools.DefaultHeaders)}
+			}
+			store.Seed(title, rows)
+		}
+		return
+	}
+}
+
+func toRow(s []string) []interface{} {
+	row := make([]interface{}, len(s))
+	for i, v := range s {
+		row[i] = v
+	}
+	return row
+}
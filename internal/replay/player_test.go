@@ -0,0 +1,61 @@
+package replay
+
+import (
+	"context"
+	"testing"
+
+	"finagent/internal/agent/tools"
+)
+
+func TestPlayAddReceipt(t *testing.T) {
+	ctx := context.Background()
+
+	f, err := LoadFixture("testdata/add_receipt.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := tools.LoadCategoryRules("../../config/category_rules.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	merchants, err := tools.LoadMerchantDictionary("../../config/merchant_aliases.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	player, err := NewPlayerWithOptions(ctx, f, PlayerOptions{CategoryRules: rules, Merchants: merchants})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := player.Play(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var appends []tools.Mutation
+	for _, m := range player.Store.Mutations() {
+		if m.Op == "append" {
+			appends = append(appends, m)
+		}
+	}
+	if len(appends) != 1 || len(appends[0].Values) != 1 {
+		t.Fatalf("got appends %+v, want one row", appends)
+	}
+
+	m := appends[0]
+	if m.SheetName != "Transaction_Tracker_20260314" {
+		t.Errorf("sheet = %q", m.SheetName)
+	}
+	row := m.Values[0]
+	want := map[int]any{
+		tools.ColNo:        1,
+		tools.ColItemName:  "Susu Ultra 1L",
+		tools.ColMerchant:  "Indomaret",
+		tools.ColCategory:  "Groceries",
+		tools.ColReceiptID: "INV-0314",
+	}
+	for col, v := range want {
+		if row[col] != v {
+			t.Errorf("column %d = %v, want %v", col, row[col], v)
+		}
+	}
+}
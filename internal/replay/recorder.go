@@ -0,0 +1,117 @@
+package replay
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+	"google.golang.org/genai"
+)
+
+// Recorder captures a live session into a Fixture. Wire it in with
+// WrapModel, BeforeAgent and AfterTool, then call Save when done.
+type Recorder struct {
+	mu      sync.Mutex
+	fixture Fixture
+}
+
+func NewRecorder(name string) *Recorder {
+	return &Recorder{
+		fixture: Fixture{
+			Name:       name,
+			RecordedAt: time.Now(),
+		},
+	}
+}
+
+// WrapModel returns a model that forwards to llm and records every call.
+func (r *Recorder) WrapModel(llm model.LLM) model.LLM {
+	r.mu.Lock()
+	r.fixture.Model = llm.Name()
+	r.mu.Unlock()
+
+	return &recordingModel{llm: llm, recorder: r}
+}
+
+// BeforeAgent records the user message that started the invocation.
+func (r *Recorder) BeforeAgent(ctx agent.CallbackContext) (*genai.Content, error) {
+	if content := ctx.UserContent(); content != nil {
+		r.mu.Lock()
+		r.fixture.UserMessages = append(r.fixture.UserMessages, content)
+		r.mu.Unlock()
+	}
+	return nil, nil
+}
+
+// AfterTool records a tool's arguments and result without changing them.
+func (r *Recorder) AfterTool(ctx tool.Context, t tool.Tool, args, result map[string]any, err error) (map[string]any, error) {
+	call := ToolCall{
+		Name:   t.Name(),
+		Args:   args,
+		Result: result,
+	}
+	if err != nil {
+		call.Error = err.Error()
+	}
+
+	r.mu.Lock()
+	r.fixture.ToolCalls = append(r.fixture.ToolCalls, call)
+	r.mu.Unlock()
+	return nil, nil
+}
+
+// Fixture returns a snapshot of what has been recorded so far.
+func (r *Recorder) Fixture() *Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f := r.fixture
+	f.UserMessages = append([]*genai.Content(nil), r.fixture.UserMessages...)
+	f.ModelCalls = append([]ModelCall(nil), r.fixture.ModelCalls...)
+	f.ToolCalls = append([]ToolCall(nil), r.fixture.ToolCalls...)
+	return &f
+}
+
+func (r *Recorder) Save(path string) error {
+	return r.Fixture().Save(path)
+}
+
+type recordingModel struct {
+	llm      model.LLM
+	recorder *Recorder
+}
+
+func (m *recordingModel) Name() string {
+	return m.llm.Name()
+}
+
+func (m *recordingModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		call := ModelCall{}
+		if len(req.Contents) > 0 {
+			call.Request = req.Contents[len(req.Contents)-1]
+		}
+
+		// Record even if the consumer stops early
+		defer func() {
+			m.recorder.mu.Lock()
+			m.recorder.fixture.ModelCalls = append(m.recorder.fixture.ModelCalls, call)
+			m.recorder.mu.Unlock()
+		}()
+
+		for resp, err := range m.llm.GenerateContent(ctx, req, stream) {
+			if err != nil {
+				call.Error = err.Error()
+			} else {
+				call.Responses = append(call.Responses, resp)
+			}
+			if !yield(resp, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Daily   = "daily"
+	Weekly  = "weekly"
+	Monthly = "monthly"
+)
+
+// Schedule is a recurring time of day: every day, on a weekday, or on a
+// day of the month (clamped to the month's last day). Times are in the
+// clock's location.
+type Schedule struct {
+	Every   string       `json:"every"`
+	Weekday time.Weekday `json:"weekday,omitempty"` // Weekly
+	Day     int          `json:"day,omitempty"`     // Monthly, 1-31
+	Hour    int          `json:"hour"`
+	Minute  int          `json:"minute"`
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday, "min": time.Sunday, "minggu": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday, "sen": time.Monday, "senin": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday, "sel": time.Tuesday, "selasa": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday, "rab": time.Wednesday, "rabu": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday, "kam": time.Thursday, "kamis": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday, "jum": time.Friday, "jumat": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday, "sab": time.Saturday, "sabtu": time.Saturday,
+}
+
+// ParseSchedule reads "daily [HH:MM]", "weekly [weekday] [HH:MM]" or
+// "monthly [day] [HH:MM]". Omitted parts default to def.
+func ParseSchedule(s string, def Schedule) (Schedule, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) == 0 {
+		return Schedule{}, fmt.Errorf("empty schedule")
+	}
+
+	sched := def
+	sched.Every = fields[0]
+	rest := fields[1:]
+
+	switch sched.Every {
+	case Daily:
+	case Weekly:
+		if len(rest) > 0 && !strings.Contains(rest[0], ":") {
+			day, ok := weekdays[rest[0]]
+			if !ok {
+				return Schedule{}, fmt.Errorf("unknown weekday %q", rest[0])
+			}
+			sched.Weekday = day
+			rest = rest[1:]
+		}
+	case Monthly:
+		if len(rest) > 0 && !strings.Contains(rest[0], ":") {
+			day, err := strconv.Atoi(rest[0])
+			if err != nil || day < 1 || day > 31 {
+				return Schedule{}, fmt.Errorf("invalid day of month %q", rest[0])
+			}
+			sched.Day = day
+			rest = rest[1:]
+		}
+	default:
+		return Schedule{}, fmt.Errorf("unknown schedule %q (use daily, weekly or monthly)", sched.Every)
+	}
+
+	if len(rest) > 0 {
+		t, err := time.Parse("15:04", rest[0])
+		if err != nil {
+			return Schedule{}, fmt.Errorf("invalid time %q (use HH:MM)", rest[0])
+		}
+		sched.Hour, sched.Minute = t.Hour(), t.Minute()
+		rest = rest[1:]
+	}
+	if len(rest) > 0 {
+		return Schedule{}, fmt.Errorf("unexpected %q", strings.Join(rest, " "))
+	}
+
+	if sched.Every == Monthly && sched.Day == 0 {
+		sched.Day = 1
+	}
+	return sched, nil
+}
+
+// Next returns the first run strictly after t.
+func (s Schedule) Next(t time.Time) time.Time {
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, s.Hour, s.Minute, 0, 0, t.Location())
+	}
+	year, month, day := t.Date()
+
+	switch s.Every {
+	case Weekly:
+		ahead := (int(s.Weekday) - int(t.Weekday()) + 7) % 7
+		next := at(year, month, day+ahead)
+		if !next.After(t) {
+			next = at(year, month, day+ahead+7)
+		}
+		return next
+
+	case Monthly:
+		next := at(year, month, clampDay(year, month, s.Day))
+		if !next.After(t) {
+			next = at(year, month+1, clampDay(year, month+1, s.Day))
+		}
+		return next
+	}
+
+	next := at(year, month, day)
+	if !next.After(t) {
+		next = at(year, month, day+1)
+	}
+	return next
+}
+
+// String formats the schedule the way ParseSchedule reads it.
+func (s Schedule) String() string {
+	clock := fmt.Sprintf("%02d:%02d", s.Hour, s.Minute)
+	switch s.Every {
+	case Weekly:
+		return fmt.Sprintf("%s %s %s", Weekly, strings.ToLower(s.Weekday.String()[:3]), clock)
+	case Monthly:
+		return fmt.Sprintf("%s %d %s", Monthly, s.Day, clock)
+	}
+	return fmt.Sprintf("%s %s", s.Every, clock)
+}
+
+// clampDay limits day to the length of the month (31 → 28 in February).
+func clampDay(year int, month time.Month, day int) int {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	return min(day, last)
+}
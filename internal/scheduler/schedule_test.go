@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*3600)
+	at := func(s string) time.Time {
+		tm, err := time.ParseInLocation("2006-01-02 15:04", s, wib)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+
+	tests := []struct {
+		name  string
+		sched Schedule
+		now   string
+		want  string
+	}{
+		{"daily later today", Schedule{Every: Daily, Hour: 21}, "2026-03-14 08:00", "2026-03-14 21:00"},
+		{"daily at the exact time", Schedule{Every: Daily, Hour: 21}, "2026-03-14 21:00", "2026-03-15 21:00"},
+		{"daily across month end", Schedule{Every: Daily, Hour: 7, Minute: 30}, "2026-03-31 08:00", "2026-04-01 07:30"},
+		{"weekly later this week", Schedule{Every: Weekly, Weekday: time.Friday, Hour: 18}, "2026-03-10 09:00", "2026-03-13 18:00"},
+		{"weekly same day, earlier", Schedule{Every: Weekly, Weekday: time.Monday, Hour: 8}, "2026-03-09 07:00", "2026-03-09 08:00"},
+		{"weekly same day, passed", Schedule{Every: Weekly, Weekday: time.Monday, Hour: 8}, "2026-03-09 08:00", "2026-03-16 08:00"},
+		{"weekly wraps past Sunday", Schedule{Every: Weekly, Weekday: time.Monday, Hour: 8}, "2026-03-14 10:00", "2026-03-16 08:00"},
+		{"weekly wraps into next month", Schedule{Every: Weekly, Weekday: time.Wednesday, Hour: 8}, "2026-03-26 10:00", "2026-04-01 08:00"},
+		{"monthly later this month", Schedule{Every: Monthly, Day: 25, Hour: 9}, "2026-03-14 10:00", "2026-03-25 09:00"},
+		{"monthly rolls to next month", Schedule{Every: Monthly, Day: 1, Hour: 9}, "2026-03-14 10:00", "2026-04-01 09:00"},
+		{"monthly rolls over the year", Schedule{Every: Monthly, Day: 5, Hour: 9}, "2026-12-20 10:00", "2027-01-05 09:00"},
+		{"monthly clamps to February", Schedule{Every: Monthly, Day: 31, Hour: 9}, "2026-01-31 10:00", "2026-02-28 09:00"},
+		{"monthly clamps to leap February", Schedule{Every: Monthly, Day: 30, Hour: 9}, "2028-02-01 10:00", "2028-02-29 09:00"},
+		{"monthly clamped day already passed", Schedule{Every: Monthly, Day: 31, Hour: 9}, "2026-04-30 10:00", "2026-05-31 09:00"},
+		{"monthly clamps in a 30-day month", Schedule{Every: Monthly, Day: 31, Hour: 9}, "2026-04-02 10:00", "2026-04-30 09:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sched.Next(at(tt.now)); !got.Equal(at(tt.want)) {
+				t.Errorf("Next(%s) = %s, want %s", tt.now, got.Format("2006-01-02 15:04"), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	def := Schedule{Every: Daily, Weekday: time.Monday, Day: 1, Hour: 9}
+
+	tests := []struct {
+		in      string
+		want    Schedule
+		wantErr bool
+	}{
+		{in: "daily", want: Schedule{Every: Daily, Weekday: time.Monday, Day: 1, Hour: 9}},
+		{in: "Daily 21:30", want: Schedule{Every: Daily, Weekday: time.Monday, Day: 1, Hour: 21, Minute: 30}},
+		{in: "weekly", want: Schedule{Every: Weekly, Weekday: time.Monday, Day: 1, Hour: 9}},
+		{in: "weekly fri 18:00", want: Schedule{Every: Weekly, Weekday: time.Friday, Day: 1, Hour: 18}},
+		{in: "weekly jumat", want: Schedule{Every: Weekly, Weekday: time.Friday, Day: 1, Hour: 9}},
+		{in: "weekly 07:15", want: Schedule{Every: Weekly, Weekday: time.Monday, Day: 1, Hour: 7, Minute: 15}},
+		{in: "monthly 25 08:00", want: Schedule{Every: Monthly, Weekday: time.Monday, Day: 25, Hour: 8}},
+		{in: "monthly 31", want: Schedule{Every: Monthly, Weekday: time.Monday, Day: 31, Hour: 9}},
+		{in: "", wantErr: true},
+		{in: "hourly", wantErr: true},
+		{in: "weekly someday", wantErr: true},
+		{in: "monthly 0", wantErr: true},
+		{in: "monthly 32", wantErr: true},
+		{in: "daily 25:00", wantErr: true},
+		{in: "daily 9", wantErr: true},
+		{in: "daily 09:00 extra", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSchedule(tt.in, def)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseSchedule(%q) = %+v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseSchedule(%q) = %+v, %v, want %+v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseScheduleMonthlyDefaultsToFirst(t *testing.T) {
+	got, err := ParseSchedule("monthly 10:00", Schedule{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Day != 1 {
+		t.Errorf("Day = %d, want 1", got.Day)
+	}
+}
+
+func TestScheduleStringRoundTrip(t *testing.T) {
+	for _, s := range []string{"daily 21:00", "weekly fri 18:30", "monthly 31 09:00"} {
+		sched, err := ParseSchedule(s, Schedule{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := sched.String(); got != s {
+			t.Errorf("String() = %q, want %q", got, s)
+		}
+	}
+}
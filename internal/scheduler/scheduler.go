@@ -0,0 +1,245 @@
+// Package scheduler runs per-chat jobs on recurring schedules (digests,
+// bill reminders) inside the bot process. Jobs are stored as JSON so they
+// survive restarts, and time comes from a Clock so runs can be simulated.
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time; tests and replays substitute their own.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// RealClock is the wall clock.
+func RealClock() Clock {
+	return realClock{}
+}
+
+// Job is one recurring task of a chat. Type says what to do ("digest",
+// "reminder"); Payload holds the type's own settings.
+type Job struct {
+	ID       string          `json:"id"`
+	ChatID   int64           `json:"chat_id"`
+	Type     string          `json:"type"`
+	Schedule Schedule        `json:"schedule"`
+	Payload  json.RawMessage `json:"payload,omitempty"`
+	Next     time.Time       `json:"next"`
+}
+
+type jobsFile struct {
+	Jobs []Job `json:"jobs"`
+}
+
+// Scheduler keeps the jobs and fires them when due. A job missed while
+// the bot was down fires once on startup, then follows its schedule.
+type Scheduler struct {
+	path  string
+	clock Clock
+
+	mu   sync.Mutex
+	jobs []Job
+	wake chan struct{}
+}
+
+// Load reads the jobs stored at path; a missing file means no jobs.
+func Load(path string, clock Clock) (*Scheduler, error) {
+	s := &Scheduler{
+		path:  path,
+		clock: clock,
+		wake:  make(chan struct{}, 1),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read schedules: %w", err)
+	}
+	if err == nil {
+		var file jobsFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		s.jobs = file.Jobs
+	}
+	return s, nil
+}
+
+// Add stores a job and returns it with its ID and next run set. A job of
+// the same chat and type with the same ID is replaced.
+func (s *Scheduler) Add(job Job) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job.ID == "" {
+		job.ID = newJobID()
+	}
+	job.Next = job.Schedule.Next(s.clock.Now())
+
+	jobs := s.without(job.ChatID, job.Type, job.ID)
+	s.jobs = append(jobs, job)
+	if err := s.saveLocked(); err != nil {
+		return Job{}, err
+	}
+
+	s.notify()
+	return job, nil
+}
+
+// Remove deletes a job of the chat; it reports whether one existed.
+func (s *Scheduler) Remove(chatID int64, jobType, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := s.without(chatID, jobType, id)
+	if len(jobs) == len(s.jobs) {
+		return false, nil
+	}
+	s.jobs = jobs
+	if err := s.saveLocked(); err != nil {
+		return false, err
+	}
+
+	s.notify()
+	return true, nil
+}
+
+// Now is the scheduler's current time, for jobs computing their period.
+func (s *Scheduler) Now() time.Time {
+	return s.clock.Now()
+}
+
+// Jobs returns the chat's jobs of a type, soonest first.
+func (s *Scheduler) Jobs(chatID int64, jobType string) []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var jobs []Job
+	for _, job := range s.jobs {
+		if job.ChatID == chatID && job.Type == jobType {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Next.Before(jobs[j].Next) })
+	return jobs
+}
+
+// Run fires due jobs until ctx is done. fire is called from this
+// goroutine and should hand slow work off.
+func (s *Scheduler) Run(ctx context.Context, fire func(Job)) {
+	for {
+		var timer <-chan time.Time
+		if next, ok := s.nextRun(); ok {
+			timer = s.clock.After(max(next.Sub(s.clock.Now()), 0))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-timer:
+			for _, job := range s.due() {
+				fire(job)
+			}
+		}
+	}
+}
+
+func (s *Scheduler) nextRun() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next time.Time
+	for _, job := range s.jobs {
+		if next.IsZero() || job.Next.Before(next) {
+			next = job.Next
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// due returns the jobs whose time has come and moves them to their next
+// run. The new times are saved first, so a crash skips a run rather than
+// repeating it.
+func (s *Scheduler) due() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	var due []Job
+	for i := range s.jobs {
+		if s.jobs[i].Next.After(now) {
+			continue
+		}
+		due = append(due, s.jobs[i])
+		s.jobs[i].Next = s.jobs[i].Schedule.Next(now)
+	}
+
+	if len(due) > 0 {
+		if err := s.saveLocked(); err != nil {
+			log.Printf("⚠️ Failed to save schedules: %v", err)
+		}
+	}
+	return due
+}
+
+// without returns the jobs except the given one; s.mu must be held.
+func (s *Scheduler) without(chatID int64, jobType, id string) []Job {
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		if job.ChatID == chatID && job.Type == jobType && job.ID == id {
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// notify wakes Run to recompute its timer.
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Scheduler) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create schedule directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(jobsFile{Jobs: s.jobs}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schedules: %w", err)
+	}
+	if err := os.WriteFile(s.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to save schedules: %w", err)
+	}
+	return nil
+}
+
+func newJobID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,139 @@
+package scheduler
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock whose time only moves when the test says so.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After fires at once for a due time and never otherwise; tests drive
+// later runs through Advance and due.
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.Now()
+	}
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestSchedulerDue(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 3, 14, 8, 0, 0, 0, time.UTC)}
+	path := filepath.Join(t.TempDir(), "schedules.json")
+
+	s, err := Load(path, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, err := s.Add(Job{ChatID: 1, Type: "digest", Schedule: Schedule{Every: Daily, Hour: 21}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 3, 14, 21, 0, 0, 0, time.UTC); !job.Next.Equal(want) {
+		t.Fatalf("Next = %s, want %s", job.Next, want)
+	}
+
+	if due := s.due(); len(due) != 0 {
+		t.Fatalf("fired early: %+v", due)
+	}
+
+	clock.Advance(13 * time.Hour)
+	due := s.due()
+	if len(due) != 1 || due[0].ID != job.ID {
+		t.Fatalf("due = %+v, want the digest", due)
+	}
+	if len(s.due()) != 0 {
+		t.Error("a job fired twice")
+	}
+
+	// The moved run time is saved
+	reloaded, err := Load(path, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs := reloaded.Jobs(1, "digest")
+	if want := time.Date(2026, 3, 15, 21, 0, 0, 0, time.UTC); len(jobs) != 1 || !jobs[0].Next.Equal(want) {
+		t.Errorf("reloaded jobs = %+v, want next run %s", jobs, want)
+	}
+}
+
+func TestSchedulerMissedRunFiresOnce(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 3, 14, 8, 0, 0, 0, time.UTC)}
+	path := filepath.Join(t.TempDir(), "schedules.json")
+
+	s, err := Load(path, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Add(Job{ChatID: 1, Type: "reminder", Schedule: Schedule{Every: Daily, Hour: 9}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Down for three days
+	clock.Advance(72 * time.Hour)
+	s, err = Load(path, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fired := make(chan Job, 10)
+	go s.Run(ctx, func(job Job) { fired <- job })
+
+	select {
+	case job := <-fired:
+		if want := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC); !job.Next.Equal(want) {
+			t.Errorf("fired the run of %s, want the missed one at %s", job.Next, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("missed run did not fire")
+	}
+
+	select {
+	case job := <-fired:
+		t.Errorf("missed run fired again: %+v", job)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSchedulerRemove(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 3, 14, 8, 0, 0, 0, time.UTC)}
+	s, err := Load("", clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, err := s.Add(Job{ChatID: 1, Type: "reminder", Schedule: Schedule{Every: Monthly, Day: 1, Hour: 9}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, _ := s.Remove(2, "reminder", job.ID); ok {
+		t.Error("another chat removed the job")
+	}
+	if ok, err := s.Remove(1, "reminder", job.ID); !ok || err != nil {
+		t.Errorf("Remove = %v, %v", ok, err)
+	}
+	if jobs := s.Jobs(1, "reminder"); len(jobs) != 0 {
+		t.Errorf("jobs left: %+v", jobs)
+	}
+}
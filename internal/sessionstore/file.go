@@ -0,0 +1,216 @@
+// Package sessionstore persists ADK sessions on disk so conversations,
+// pending confirmations and user state survive restarts.
+package sessionstore
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// FileService is a session.Service that keeps sessions in memory and
+// journals them to one JSONL file per session: a header line, then one
+// line per event. On startup the journals are replayed.
+//
+// Inline images and audio are not journaled (see withoutBlobs): the running
+// conversation keeps them, a restored one sees a short note instead.
+type FileService struct {
+	session.Service // in-memory sessions, the source of truth while running
+
+	dir string
+	mu  sync.Mutex
+}
+
+// header is the first line of a session journal.
+type header struct {
+	AppName   string         `json:"appName"`
+	UserID    string         `json:"userId"`
+	SessionID string         `json:"sessionId"`
+	State     map[string]any `json:"state,omitempty"`
+}
+
+// NewFileService loads every session journal in dir (created if missing).
+func NewFileService(ctx context.Context, dir string) (*FileService, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	s := &FileService{
+		Service: session.InMemoryService(),
+		dir:     dir,
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	for _, file := range files {
+		if err := s.load(ctx, file); err != nil {
+			// One broken journal should not take every conversation down
+			log.Printf("⚠️ Skipping session %s: %v", filepath.Base(file), err)
+		}
+	}
+
+	log.Printf("✓ Loaded %d sessions from %s", len(files), dir)
+	return s, nil
+}
+
+func (s *FileService) Create(ctx context.Context, req *session.CreateRequest) (*session.CreateResponse, error) {
+	resp, err := s.Service.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := header{
+		AppName:   req.AppName,
+		UserID:    req.UserID,
+		SessionID: resp.Session.ID(),
+		State:     req.State,
+	}
+	if err := s.write(h.SessionID, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, h); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *FileService) Delete(ctx context.Context, req *session.DeleteRequest) error {
+	if err := s.Service.Delete(ctx, req); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(req.SessionID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete session file: %w", err)
+	}
+	return nil
+}
+
+func (s *FileService) AppendEvent(ctx context.Context, sess session.Session, event *session.Event) error {
+	if err := s.Service.AppendEvent(ctx, sess, event); err != nil {
+		return err
+	}
+
+	// Streamed chunks are not stored; the complete event follows
+	if event.Partial {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.write(sess.ID(), os.O_APPEND|os.O_WRONLY, withoutBlobs(event))
+}
+
+// withoutBlobs returns event with its inline data parts replaced by a text
+// note, so a receipt photo does not add megabytes of base64 to the
+// journal. The receipt is already in the spreadsheet once processed.
+func withoutBlobs(event *session.Event) *session.Event {
+	if event.Content == nil {
+		return event
+	}
+
+	var parts []*genai.Part
+	for i, part := range event.Content.Parts {
+		if part == nil || part.InlineData == nil {
+			if parts != nil {
+				parts = append(parts, part)
+			}
+			continue
+		}
+		if parts == nil {
+			parts = append(make([]*genai.Part, 0, len(event.Content.Parts)), event.Content.Parts[:i]...)
+		}
+		note := fmt.Sprintf("[%s attachment of %d KB, not kept after a restart]", part.InlineData.MIMEType, len(part.InlineData.Data)/1024)
+		parts = append(parts, genai.NewPartFromText(note))
+	}
+	if parts == nil {
+		return event
+	}
+
+	stored := *event
+	content := *event.Content
+	content.Parts = parts
+	stored.Content = &content
+	return &stored
+}
+
+// write encodes v as one line of the session's journal.
+func (s *FileService) write(sessionID string, flag int, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode session %s: %w", sessionID, err)
+	}
+
+	f, err := os.OpenFile(s.path(sessionID), flag, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open session file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write session file: %w", err)
+	}
+	return nil
+}
+
+// load replays a journal into the in-memory service.
+func (s *FileService) load(ctx context.Context, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	// Journals written before blobs were stripped may hold inline images
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	if !scanner.Scan() {
+		return fmt.Errorf("empty journal")
+	}
+	var h header
+	if err := json.Unmarshal(scanner.Bytes(), &h); err != nil {
+		return fmt.Errorf("invalid header: %w", err)
+	}
+
+	resp, err := s.Service.Create(ctx, &session.CreateRequest{
+		AppName:   h.AppName,
+		UserID:    h.UserID,
+		SessionID: h.SessionID,
+		State:     h.State,
+	})
+	if err != nil {
+		return err
+	}
+
+	for scanner.Scan() {
+		var event session.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			// A line cut short by a crash; keep what came before
+			log.Printf("⚠️ Session %s: dropping unreadable event: %v", h.SessionID, err)
+			break
+		}
+		if err := s.Service.AppendEvent(ctx, resp.Session, &event); err != nil {
+			return fmt.Errorf("failed to replay event: %w", err)
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *FileService) path(sessionID string) string {
+	return filepath.Join(s.dir, url.PathEscape(sessionID)+".jsonl")
+}
@@ -0,0 +1,69 @@
+package sessionstore
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+func TestFileServiceStripsBlobs(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	svc, err := NewFileService(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := svc.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "tg_1", SessionID: "s1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	photo := bytes.Repeat([]byte{0xFF}, 512*1024)
+	event := session.NewEvent("inv1")
+	event.Author = "user"
+	event.Content = genai.NewContentFromParts([]*genai.Part{
+		genai.NewPartFromText("add this receipt"),
+		genai.NewPartFromBytes(photo, "image/jpeg"),
+	}, genai.RoleUser)
+	if err := svc.AppendEvent(ctx, created.Session, event); err != nil {
+		t.Fatal(err)
+	}
+
+	// The running session keeps the image
+	if event.Content.Parts[1].InlineData == nil {
+		t.Fatal("the appended event lost its image")
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "s1.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() > 4*1024 {
+		t.Errorf("journal is %d bytes, the image was stored", info.Size())
+	}
+
+	restored, err := NewFileService(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := restored.Get(ctx, &session.GetRequest{AppName: "app", UserID: "tg_1", SessionID: "s1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := got.Session.Events()
+	if events.Len() != 1 {
+		t.Fatalf("restored %d events, want 1", events.Len())
+	}
+	parts := events.At(0).Content.Parts
+	if len(parts) != 2 || parts[0].Text != "add this receipt" || parts[1].InlineData != nil ||
+		!strings.Contains(parts[1].Text, "image/jpeg attachment of 512 KB") {
+		t.Errorf("restored parts %+v", parts)
+	}
+}
@@ -0,0 +1,288 @@
+// go-agent-tracker/internal/telegram/access.go
+package telegram
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/tool"
+)
+
+type Role string
+
+const (
+	RoleOwner    Role = "owner"
+	RoleMember   Role = "member"
+	RoleReadOnly Role = "readonly"
+)
+
+// InviteTTL is how long an invite code can be redeemed.
+const InviteTTL = 24 * time.Hour
+
+// QueryTools are the tools available to read-only users.
+var QueryTools = map[string]bool{
+	"list_sheets":     true,
+	"read_from_sheet": true,
+}
+
+// OwnerTools are the tools only owners may run, because they change
+// configuration shared by every chat.
+var OwnerTools = map[string]bool{
+	"add_merchant_alias": true,
+}
+
+// ParseRole maps "member", "readonly" (or "read-only") and "owner" to a Role.
+func ParseRole(s string) (Role, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "owner":
+		return RoleOwner, true
+	case "member":
+		return RoleMember, true
+	case "readonly", "read-only", "viewer":
+		return RoleReadOnly, true
+	}
+	return "", false
+}
+
+// CanWrite reports whether the role may run or confirm mutating tools.
+func (r Role) CanWrite() bool {
+	return r == RoleOwner || r == RoleMember
+}
+
+type Member struct {
+	ID      int64     `json:"id"`
+	Name    string    `json:"name,omitempty"`
+	Role    Role      `json:"role"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+type Invite struct {
+	Code      string    `json:"code"`
+	Role      Role      `json:"role"`
+	CreatedBy int64     `json:"created_by"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type accessFile struct {
+	Members []Member `json:"members"`
+	Invites []Invite `json:"invites,omitempty"`
+}
+
+// AccessList is the allowlist of Telegram user IDs with their roles,
+// stored as JSON. Owners given at load time are always present.
+type AccessList struct {
+	path string
+	mu   sync.Mutex
+	data accessFile
+}
+
+func LoadAccessList(path string, owners []int64) (*AccessList, error) {
+	al := &AccessList{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read access list: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &al.data); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+
+	for _, id := range owners {
+		if m := al.member(id); m != nil {
+			m.Role = RoleOwner
+			continue
+		}
+		al.data.Members = append(al.data.Members, Member{ID: id, Role: RoleOwner, AddedAt: time.Now()})
+	}
+
+	return al, nil
+}
+
+// Role returns the role of a Telegram user; ok is false for strangers.
+func (al *AccessList) Role(userID int64) (Role, bool) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	if m := al.member(userID); m != nil {
+		return m.Role, true
+	}
+	return "", false
+}
+
+// Members returns a copy of the allowlist.
+func (al *AccessList) Members() []Member {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	return append([]Member(nil), al.data.Members...)
+}
+
+// CreateInvite issues a single-use code for role. Only owners can invite.
+func (al *AccessList) CreateInvite(by int64, role Role) (Invite, error) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	if m := al.member(by); m == nil || m.Role != RoleOwner {
+		return Invite{}, fmt.Errorf("only owners can create invites")
+	}
+	if role == RoleOwner {
+		return Invite{}, fmt.Errorf("owners are configured, not invited")
+	}
+
+	b := make([]byte, 4)
+	rand.Read(b)
+	invite := Invite{
+		Code:      strings.ToUpper(hex.EncodeToString(b)),
+		Role:      role,
+		CreatedBy: by,
+		ExpiresAt: time.Now().Add(InviteTTL),
+	}
+
+	al.data.Invites = append(al.pruneInvites(), invite)
+	if err := al.saveLocked(); err != nil {
+		return Invite{}, err
+	}
+	return invite, nil
+}
+
+// Join redeems an invite code and adds the user with the invite's role.
+func (al *AccessList) Join(userID int64, name, code string) (Role, error) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	if m := al.member(userID); m != nil {
+		return m.Role, nil
+	}
+
+	invites := al.pruneInvites()
+	code = strings.ToUpper(strings.TrimSpace(code))
+	for i, inv := range invites {
+		if inv.Code != code {
+			continue
+		}
+
+		al.data.Invites = append(invites[:i:i], invites[i+1:]...)
+		al.data.Members = append(al.data.Members, Member{
+			ID:      userID,
+			Name:    name,
+			Role:    inv.Role,
+			AddedAt: time.Now(),
+		})
+		if err := al.saveLocked(); err != nil {
+			return "", err
+		}
+		return inv.Role, nil
+	}
+
+	return "", fmt.Errorf("invalid or expired invite code")
+}
+
+// Revoke removes a member. Owners cannot be revoked here.
+func (al *AccessList) Revoke(by, userID int64) error {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	if m := al.member(by); m == nil || m.Role != RoleOwner {
+		return fmt.Errorf("only owners can revoke access")
+	}
+
+	for i, m := range al.data.Members {
+		if m.ID != userID {
+			continue
+		}
+		if m.Role == RoleOwner {
+			return fmt.Errorf("cannot revoke an owner")
+		}
+		al.data.Members = append(al.data.Members[:i], al.data.Members[i+1:]...)
+		return al.saveLocked()
+	}
+	return fmt.Errorf("user %d is not a member", userID)
+}
+
+func (al *AccessList) member(userID int64) *Member {
+	for i := range al.data.Members {
+		if al.data.Members[i].ID == userID {
+			return &al.data.Members[i]
+		}
+	}
+	return nil
+}
+
+func (al *AccessList) pruneInvites() []Invite {
+	var valid []Invite
+	for _, inv := range al.data.Invites {
+		if time.Now().Before(inv.ExpiresAt) {
+			valid = append(valid, inv)
+		}
+	}
+	return valid
+}
+
+func (al *AccessList) saveLocked() error {
+	if al.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(al.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create access directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(al.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode access list: %w", err)
+	}
+	if err := os.WriteFile(al.path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to save access list: %w", err)
+	}
+	return nil
+}
+
+type roleKey struct{}
+
+// WithRole attaches the caller's role to the context of an agent run.
+func WithRole(ctx context.Context, role Role) context.Context {
+	return context.WithValue(ctx, roleKey{}, role)
+}
+
+// RoleFromContext returns the role set by WithRole.
+func RoleFromContext(ctx context.Context) (Role, bool) {
+	role, ok := ctx.Value(roleKey{}).(Role)
+	return role, ok
+}
+
+type senderKey struct{}
+
+// WithSender attaches the Telegram user ID of whoever triggered an agent
+// run, which may differ from the chat in groups.
+func WithSender(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, senderKey{}, userID)
+}
+
+// SenderFromContext returns the user ID set by WithSender.
+func SenderFromContext(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(senderKey{}).(int64)
+	return id, ok
+}
+
+// ToolFilter hides everything but QueryTools from read-only users and
+// OwnerTools from members. Runs without a role (CLI, eval) see all tools.
+func ToolFilter(ctx agent.ReadonlyContext, t tool.Tool) bool {
+	role, ok := RoleFromContext(ctx)
+	switch {
+	case !ok || role == RoleOwner:
+		return true
+	case role.CanWrite():
+		return !OwnerTools[t.Name()]
+	}
+	return QueryTools[t.Name()]
+}
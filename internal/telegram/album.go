@@ -0,0 +1,81 @@
+// go-agent-tracker/internal/telegram/album.go
+package telegram
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// albumBuffer collects the messages of a media group. Telegram delivers an
+// album as separate updates with the same MediaGroupID, so the group is
+// flushed once no new message arrived for wait.
+type albumBuffer struct {
+	mu     sync.Mutex
+	wait   time.Duration
+	groups map[string]*album
+}
+
+type album struct {
+	msgs    []*tgbotapi.Message
+	timer   *time.Timer
+	flushed bool
+	flush   func()
+}
+
+func newAlbumBuffer(wait time.Duration) *albumBuffer {
+	return &albumBuffer{
+		wait:   wait,
+		groups: make(map[string]*album),
+	}
+}
+
+// Add buffers msg. flush of the group's first message is called once with
+// all messages in send order.
+func (b *albumBuffer) Add(msg *tgbotapi.Message, flush func([]*tgbotapi.Message)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := msg.MediaGroupID
+	if a, ok := b.groups[id]; ok {
+		a.msgs = append(a.msgs, msg)
+		a.timer.Reset(b.wait)
+		return
+	}
+
+	a := &album{msgs: []*tgbotapi.Message{msg}}
+	a.flush = func() {
+		b.mu.Lock()
+		// A Reset racing with the first expiry fires the timer again
+		if a.flushed {
+			b.mu.Unlock()
+			return
+		}
+		a.flushed = true
+		msgs := a.msgs
+		delete(b.groups, id)
+		b.mu.Unlock()
+
+		sort.Slice(msgs, func(i, j int) bool { return msgs[i].MessageID < msgs[j].MessageID })
+		flush(msgs)
+	}
+	a.timer = time.AfterFunc(b.wait, a.flush)
+	b.groups[id] = a
+}
+
+// FlushAll flushes every buffered album now, e.g. on shutdown.
+func (b *albumBuffer) FlushAll() {
+	b.mu.Lock()
+	albums := make([]*album, 0, len(b.groups))
+	for _, a := range b.groups {
+		albums = append(albums, a)
+	}
+	b.mu.Unlock()
+
+	for _, a := range albums {
+		a.timer.Stop()
+		a.flush() // no-op if the timer fired meanwhile
+	}
+}
@@ -0,0 +1,280 @@
+// go-agent-tracker/internal/telegram/bills.go
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"finagent/internal/agent/tools"
+	"finagent/internal/i18n"
+	"finagent/internal/scheduler"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Bills are recurring expenses (rent, electricity, internet). On schedule
+// the bot sends a reminder whose "Log it" button appends the transaction
+// to the spreadsheet, without a model call.
+
+const billJob = "reminder"
+
+// defaultBill is the schedule when only "monthly" is given: the 1st, 09:00.
+var defaultBill = scheduler.Schedule{Every: scheduler.Monthly, Day: 1, Hour: 9}
+
+// bill is the payload of a reminder job.
+type bill struct {
+	Item     string  `json:"item"`
+	Amount   float64 `json:"amount"`
+	Merchant string  `json:"merchant"`
+	Category string  `json:"category"`
+}
+
+// handleBills: /bills lists the chat's bills; /bills add <item> | <amount>
+// | <merchant> | <schedule> [| <category>] registers one; /bills remove
+// <id> deletes it.
+func (tb *TelegramBot) handleBills(msg *tgbotapi.Message, lang i18n.Lang) {
+	chatID := msg.Chat.ID
+
+	role, ok := tb.role(msg.From)
+	if !ok || tb.scheduler == nil {
+		tb.sendMessage(chatID, i18n.T(lang, "access.denied"), false)
+		return
+	}
+
+	action, rest, _ := strings.Cut(strings.TrimSpace(msg.CommandArguments()), " ")
+	action = strings.ToLower(action)
+	if action == "" {
+		tb.sendMessage(chatID, tb.billList(chatID, lang), false)
+		return
+	}
+	if !role.CanWrite() {
+		tb.sendMessage(chatID, i18n.T(lang, "access.denied"), false)
+		return
+	}
+
+	switch action {
+	case "add":
+		tb.addBill(chatID, lang, rest)
+	case "remove":
+		ok, err := tb.scheduler.Remove(chatID, billJob, strings.TrimSpace(rest))
+		if err != nil {
+			tb.ledgerError(chatID, lang, "bill_remove", err)
+			return
+		}
+		if !ok {
+			tb.sendMessage(chatID, i18n.T(lang, "bills.unknown", strings.TrimSpace(rest)), false)
+			return
+		}
+		tb.sendMessage(chatID, i18n.T(lang, "bills.removed"), false)
+	default:
+		tb.sendMessage(chatID, i18n.T(lang, "bills.usage"), false)
+	}
+}
+
+func (tb *TelegramBot) addBill(chatID int64, lang i18n.Lang, args string) {
+	b, sched, err := parseBill(args)
+	if err != nil {
+		tb.sendMessage(chatID, i18n.T(lang, "bills.invalid", err)+"\n\n"+i18n.T(lang, "bills.usage"), false)
+		return
+	}
+
+	payload, err := json.Marshal(b)
+	if err != nil {
+		tb.ledgerError(chatID, lang, "bill_add", err)
+		return
+	}
+	job, err := tb.scheduler.Add(scheduler.Job{
+		ChatID:   chatID,
+		Type:     billJob,
+		Schedule: sched,
+		Payload:  payload,
+	})
+	if err != nil {
+		tb.ledgerError(chatID, lang, "bill_add", err)
+		return
+	}
+
+	log.Printf("🔔 Chat %d: bill %s (%s) at %s", chatID, job.ID, b.Item, sched)
+	tb.sendMessage(chatID, i18n.T(lang, "bills.set", b.Item, tools.FormatRupiah(b.Amount), sched, job.Next.Format("Mon 2 Jan 15:04"), job.ID), false)
+}
+
+// parseBill reads "<item> | <amount> | <merchant> | <schedule> [|
+// <category>]". The row is validated like one the agent would append.
+func parseBill(args string) (bill, scheduler.Schedule, error) {
+	parts := strings.Split(args, "|")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	if len(parts) < 4 || len(parts) > 5 {
+		return bill{}, scheduler.Schedule{}, fmt.Errorf("expected 4 or 5 parts separated by |, got %d", len(parts))
+	}
+
+	amount, ok := tools.ParseAmount(parts[1])
+	if !ok || amount <= 0 {
+		return bill{}, scheduler.Schedule{}, fmt.Errorf("invalid amount %q", parts[1])
+	}
+	b := bill{Item: parts[0], Amount: amount, Merchant: parts[2], Category: "Bills"}
+	if len(parts) == 5 && parts[4] != "" {
+		b.Category = parts[4]
+	}
+
+	sched, err := scheduler.ParseSchedule(parts[3], defaultBill)
+	if err != nil {
+		return bill{}, scheduler.Schedule{}, err
+	}
+	if err := tools.ValidateRows([][]interface{}{b.row("", "BILL")}); err != nil {
+		return bill{}, scheduler.Schedule{}, err
+	}
+	return b, sched, nil
+}
+
+// row is the bill as a transaction row.
+func (b bill) row(date, receiptID string) []interface{} {
+	return []interface{}{
+		"", b.Item, 1, "", b.Amount, b.Amount, b.Category, b.Merchant,
+		date, "reminder", receiptID,
+	}
+}
+
+func (tb *TelegramBot) billList(chatID int64, lang i18n.Lang) string {
+	jobs := tb.scheduler.Jobs(chatID, billJob)
+	if len(jobs) == 0 {
+		return i18n.T(lang, "bills.none") + "\n\n" + i18n.T(lang, "bills.usage")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, "bills.list"))
+	for _, job := range jobs {
+		var b bill
+		if err := json.Unmarshal(job.Payload, &b); err != nil {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n• %s — %s, %s (%s) [%s]", b.Item, tools.FormatRupiah(b.Amount), job.Schedule, job.Next.Format("Mon 2 Jan 15:04"), job.ID))
+	}
+	return sb.String()
+}
+
+// sendBillReminder sends the reminder of a bill job with Log it / Skip
+// buttons. The buttons carry the reminder's date, so each reminder is
+// logged at most once.
+func (tb *TelegramBot) sendBillReminder(job scheduler.Job) {
+	chatID := job.ChatID
+	lang := tb.runner.Language(tb.ctx, chatID, "")
+
+	var b bill
+	if err := json.Unmarshal(job.Payload, &b); err != nil {
+		log.Printf("⚠️ Chat %d: invalid bill %s: %v", chatID, job.ID, err)
+		return
+	}
+
+	date := tb.scheduler.Now().Format("20060102")
+	msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "bills.reminder", b.Item, tools.FormatRupiah(b.Amount), b.Merchant))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "bills.log"), billCallbackData("log", job.ID, date)),
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "bills.skip"), billCallbackData("skip", job.ID, date)),
+		),
+	)
+
+	log.Printf("🔔 Chat %d: reminded of bill %s", chatID, job.ID)
+	tb.send(msg, false)
+}
+
+// billCallbackData encodes a reminder button as "bill:<log|skip>:<job
+// id>:<YYYYMMDD>".
+func billCallbackData(action, jobID, date string) string {
+	return fmt.Sprintf("bill:%s:%s:%s", action, jobID, date)
+}
+
+// logBill appends the bill to today's sheet unless a row with receiptID
+// exists, and returns the sheet holding it. The duplicate check must not
+// be skipped, so a failed read is an error.
+func logBill(ctx context.Context, b bill, receiptID string, due time.Time) (string, error) {
+	txs, err := tools.Transactions(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to check for a logged bill: %w", err)
+	}
+	for _, tx := range txs {
+		if tx.ReceiptID == receiptID {
+			return tx.Sheet, nil
+		}
+	}
+
+	sheet, err := tools.TodaySheet(ctx)
+	if err != nil {
+		return "", err
+	}
+	row := b.row(due.Format("2006-01-02"), receiptID)
+	if err := tools.AppendToSheet(ctx, sheet, [][]interface{}{row}); err != nil {
+		return "", err
+	}
+	return sheet, nil
+}
+
+// handleBillCallback applies a reminder button. Logging appends the bill
+// through tools.AppendToSheet to today's sheet, unless the reminder was
+// already logged.
+func (tb *TelegramBot) handleBillCallback(cb *tgbotapi.CallbackQuery) {
+	chatID := cb.Message.Chat.ID
+	lang := tb.language(chatID, cb.From)
+
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 4 || parts[0] != "bill" {
+		tb.bot.Request(tgbotapi.NewCallback(cb.ID, ""))
+		return
+	}
+	action, jobID, date := parts[1], parts[2], parts[3]
+
+	if action == "skip" {
+		tb.bot.Request(tgbotapi.NewCallback(cb.ID, i18n.T(lang, "bills.skipped")))
+		tb.editConfirmation(cb.Message, i18n.T(lang, "bills.skipped"))
+		return
+	}
+
+	var b bill
+	found := false
+	if tb.scheduler != nil {
+		for _, job := range tb.scheduler.Jobs(chatID, billJob) {
+			if job.ID == jobID {
+				found = json.Unmarshal(job.Payload, &b) == nil
+				break
+			}
+		}
+	}
+	if !found {
+		tb.bot.Request(tgbotapi.NewCallback(cb.ID, i18n.T(lang, "notice.handled")))
+		tb.editConfirmation(cb.Message, i18n.T(lang, "bills.gone"))
+		return
+	}
+
+	ctx := withGroupAuthor(tb.ledgerContext(chatID), cb.Message.Chat, cb.From)
+	receiptID := fmt.Sprintf("BILL_%s_%s", jobID, date)
+
+	// The row is dated when the reminder was sent, like its receipt ID,
+	// even if the button is pressed days later
+	due, err := time.ParseInLocation("20060102", date, time.Local)
+	var sheet string
+	if err == nil {
+		sheet, err = logBill(ctx, b, receiptID, due)
+	}
+	if err != nil {
+		tb.runner.logger.LogError(ErrorLog{
+			ChatID:    chatID,
+			UserID:    fmt.Sprintf("tg_%d", chatID),
+			Component: "bill_log",
+			Error:     err.Error(),
+			Details:   fmt.Sprintf("Callback: %s", cb.Data),
+		})
+		tb.bot.Request(tgbotapi.NewCallback(cb.ID, i18n.T(lang, "notice.failed")))
+		tb.sendMessage(chatID, i18n.T(lang, "ledger.failed", err), false)
+		return
+	}
+
+	log.Printf("🔔 Chat %d: logged bill %s to %s", chatID, jobID, sheet)
+	tb.bot.Request(tgbotapi.NewCallback(cb.ID, i18n.T(lang, "notice.saved")))
+	tb.editConfirmation(cb.Message, i18n.T(lang, "bills.logged", sheet))
+}
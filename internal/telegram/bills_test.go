@@ -0,0 +1,52 @@
+package telegram
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"finagent/internal/agent/tools"
+)
+
+func TestLogBill(t *testing.T) {
+	b := bill{Item: "Internet", Amount: 350000, Merchant: "Biznet", Category: "Bills"}
+	due := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	ctx := context.Background()
+
+	// No spreadsheet: the duplicate check fails, nothing is appended
+	tools.SetSheetStore(nil)
+	if _, err := logBill(ctx, b, "BILL_ab12_20260301", due); err == nil {
+		t.Fatal("logged a bill without checking for a duplicate")
+	}
+
+	store := tools.NewMemorySheetStore()
+	tools.SetSheetStore(store)
+	tools.SetCategoryRules([]tools.CategoryRule{})
+	tools.SetMerchantDictionary(&tools.MerchantDictionary{})
+	tools.SetClock(func() time.Time { return time.Date(2026, 3, 4, 10, 0, 0, 0, time.Local) })
+	defer func() {
+		tools.SetSheetStore(nil)
+		tools.SetClock(nil)
+	}()
+
+	for i := 0; i < 2; i++ {
+		sheet, err := logBill(ctx, b, "BILL_ab12_20260301", due)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sheet != "Transaction_Tracker_20260304" {
+			t.Errorf("sheet = %q", sheet)
+		}
+	}
+
+	rows := store.Rows("Transaction_Tracker_20260304")
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want the header and one bill", len(rows))
+	}
+	if got := rows[1][tools.ColReceiptDate]; got != "2026-03-01" {
+		t.Errorf("receipt_date = %v, want the reminder's date", got)
+	}
+	if got := rows[1][tools.ColReceiptID]; got != "BILL_ab12_20260301" {
+		t.Errorf("receipt_id = %v", got)
+	}
+}
@@ -3,25 +3,56 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"finagent/internal/agent/hitl"
+	"finagent/internal/agent/tools"
+	"finagent/internal/i18n"
+	"finagent/internal/imageprep"
+	"finagent/internal/scheduler"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 type TelegramBot struct {
 	bot    *tgbotapi.BotAPI
 	runner *BotRunner
+	access *AccessList
 	config BotConfig
-	ctx    context.Context
+	queue  *chatQueue
+	albums *albumBuffer
+
+	// scheduler pushes digests; nil disables them
+	scheduler *scheduler.Scheduler
+
+	// ctx is the context of agent runs and tool calls; cancel aborts them
+	// when shutdown times out. stopping is closed when shutdown begins.
+	ctx         context.Context
+	cancel      context.CancelFunc
+	stopping    chan struct{}
+	stopOnce    sync.Once
+	loopDone    chan struct{} // closed when Start returns
+	running     atomic.Bool
+	updates     <-chan tgbotapi.Update
+	server      *http.Server // webhook mode
+	interrupted atomic.Int64 // turns aborted by shutdown
+
+	// transcriber turns voice notes into text; nil sends the audio itself
+	// to the model.
+	transcriber Transcriber
 }
 
-func NewTelegramBot(token string, runner *BotRunner, config BotConfig) (*TelegramBot, error) {
+func NewTelegramBot(token string, runner *BotRunner, access *AccessList, config BotConfig) (*TelegramBot, error) {
 	bot, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bot: %w", err)
@@ -29,51 +60,230 @@ func NewTelegramBot(token string, runner *BotRunner, config BotConfig) (*Telegra
 
 	log.Printf("✓ Authorized on account @%s", bot.Self.UserName)
 
+	ctx, cancel := context.WithCancel(context.Background())
 	return &TelegramBot{
-		bot:    bot,
-		runner: runner,
-		config: config,
-		ctx:    context.Background(),
+		bot:      bot,
+		runner:   runner,
+		access:   access,
+		config:   config,
+		queue:    newChatQueue(config.MaxQueuedMessages),
+		albums:   newAlbumBuffer(config.MediaGroupWait),
+		ctx:      ctx,
+		cancel:   cancel,
+		stopping: make(chan struct{}),
+		loopDone: make(chan struct{}),
 	}, nil
 }
 
+// Start receives updates by long polling, or through the webhook server
+// when WebhookListen is set. Both feed handleUpdate. It returns once
+// Shutdown is called.
 func (tb *TelegramBot) Start() error {
+	tb.running.Store(true)
+	defer close(tb.loopDone)
+
+	tb.registerCommands()
+	go tb.runScheduler()
+
+	if tb.config.WebhookListen != "" {
+		updates, errs, err := tb.webhookUpdates()
+		if err != nil {
+			return err
+		}
+		tb.updates = updates
+
+		log.Println("🤖 Bot started in webhook mode, waiting for messages...")
+		for {
+			select {
+			case <-tb.stopping:
+				return nil
+			case update := <-updates:
+				tb.handleUpdate(update)
+			case err := <-errs:
+				if errors.Is(err, http.ErrServerClosed) {
+					return nil
+				}
+				return fmt.Errorf("webhook server stopped: %w", err)
+			}
+		}
+	}
+
+	// A webhook left over from a previous deployment blocks getUpdates
+	tb.bot.Request(tgbotapi.DeleteWebhookConfig{})
+
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
 	updates := tb.bot.GetUpdatesChan(u)
+	tb.updates = updates
 
 	log.Println("🤖 Bot started, waiting for messages...")
 
-	for update := range updates {
-		if update.Message == nil {
-			continue
+	for {
+		select {
+		case <-tb.stopping:
+			return nil
+		case update, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			tb.handleUpdate(update)
 		}
+	}
+}
 
-		// Only handle private chats
-		if update.Message.Chat.Type != "private" {
-			continue
+// drain handles the updates already received.
+func (tb *TelegramBot) drain(updates <-chan tgbotapi.Update) {
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			tb.handleUpdate(update)
+		default:
+			return
 		}
+	}
+}
 
-		// Handle commands
-		if update.Message.IsCommand() {
-			tb.handleCommand(update.Message)
-			continue
+// Shutdown stops receiving updates and waits up to timeout for the queued
+// and running turns. Turns still running then are cancelled; their chats
+// are told to send the message again. Sessions and pending confirmations
+// are already on disk.
+func (tb *TelegramBot) Shutdown(timeout time.Duration) {
+	first := false
+	tb.stopOnce.Do(func() {
+		first = true
+		close(tb.stopping)
+	})
+	if !first {
+		return
+	}
+
+	if tb.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		tb.server.Shutdown(ctx)
+		cancel()
+	} else {
+		tb.bot.StopReceivingUpdates()
+	}
+	if tb.running.Load() {
+		<-tb.loopDone
+		// Received updates count as delivered; the batch of a poll still
+		// in flight is not confirmed and comes again after a restart
+		tb.drain(tb.updates)
+	}
+
+	// Albums waiting for more photos are handled now rather than lost
+	tb.albums.FlushAll()
+	tb.queue.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := tb.queue.Wait(ctx); err == nil {
+		log.Println("✓ All messages processed")
+		return
+	}
+
+	log.Printf("⚠️ Messages still running after %s, cancelling them", timeout)
+	tb.cancel()
+
+	// Give the cancelled turns a moment to notify their chats
+	grace, cancelGrace := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelGrace()
+	tb.queue.Wait(grace)
+	log.Printf("⚠️ %d messages interrupted by shutdown", tb.interrupted.Load())
+}
+
+func (tb *TelegramBot) handleUpdate(update tgbotapi.Update) {
+	// Confirmation, bill and undo buttons write to the sheet, so they share the
+	// chat queue
+	if cb := update.CallbackQuery; cb != nil {
+		if cb.Message == nil {
+			return
 		}
+		lang := tb.language(cb.Message.Chat.ID, cb.From)
 
-		// Handle regular messages
-		go tb.handleMessage(update.Message)
+		role, ok := tb.role(cb.From)
+		if !ok || !role.CanWrite() {
+			tb.bot.Request(tgbotapi.NewCallback(cb.ID, i18n.T(lang, "notice.denied")))
+			return
+		}
+		job := func() { tb.handleCallback(cb, role) }
+		switch {
+		case strings.HasPrefix(cb.Data, "bill:"):
+			job = func() { tb.handleBillCallback(cb) }
+		case strings.HasPrefix(cb.Data, "undo:"):
+			job = func() { tb.handleUndoCallback(cb) }
+		}
+		if !tb.queue.Submit(cb.Message.Chat.ID, job) {
+			tb.bot.Request(tgbotapi.NewCallback(cb.ID, i18n.T(lang, "busy")))
+		}
+		return
 	}
 
-	return nil
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+
+	// Private chats always; groups when enabled, and only the messages
+	// meant for the bot (an album is checked once complete)
+	group := isGroup(msg.Chat)
+	switch {
+	case msg.Chat.IsPrivate():
+	case group && tb.config.EnableGroups:
+		if msg.MediaGroupID == "" && !tb.addressed(msg) {
+			return
+		}
+	default:
+		return
+	}
+
+	// Handle commands
+	if msg.IsCommand() {
+		tb.handleCommand(msg)
+		return
+	}
+
+	// Only allowlisted users reach the agent
+	role, ok := tb.role(msg.From)
+	if !ok {
+		if msg.MediaGroupID == "" || !group {
+			tb.sendMessage(msg.Chat.ID, i18n.T(tb.language(msg.Chat.ID, msg.From), "access.denied"), false)
+		}
+		return
+	}
+
+	// Album photos arrive as separate updates; collect them into one turn
+	if msg.MediaGroupID != "" {
+		tb.albums.Add(msg, func(msgs []*tgbotapi.Message) {
+			if group && !tb.anyAddressed(msgs) {
+				return
+			}
+			if !tb.queue.Submit(msg.Chat.ID, func() { tb.handleMessages(msgs, role) }) {
+				tb.sendMessage(msg.Chat.ID, i18n.T(tb.language(msg.Chat.ID, msg.From), "busy"), false)
+			}
+		})
+		return
+	}
+
+	// Handle regular messages one at a time per chat
+	if !tb.queue.Submit(msg.Chat.ID, func() { tb.handleMessage(msg, role) }) {
+		tb.sendMessage(msg.Chat.ID, i18n.T(tb.language(msg.Chat.ID, msg.From), "busy"), false)
+	}
 }
 
 func (tb *TelegramBot) Cleanup() {
 	// Clean up temp files on shutdown
-	pattern := filepath.Join(tb.config.PhotoTempDir, "tg_photo_*.jpg")
-	files, err := filepath.Glob(pattern)
-	if err != nil {
-		return
+	var files []string
+	for _, pattern := range []string{"tg_photo_*", "tg_doc_*", "tg_voice_*"} {
+		matches, err := filepath.Glob(filepath.Join(tb.config.PhotoTempDir, pattern))
+		if err != nil {
+			return
+		}
+		files = append(files, matches...)
 	}
 
 	for _, file := range files {
@@ -84,55 +294,171 @@ func (tb *TelegramBot) Cleanup() {
 }
 
 func (tb *TelegramBot) handleCommand(msg *tgbotapi.Message) {
+	lang := tb.language(msg.Chat.ID, msg.From)
+
+	// Invite codes and member lists stay out of groups
+	switch msg.Command() {
+	case "invite", "join", "revoke", "users":
+		if !msg.Chat.IsPrivate() {
+			tb.sendMessage(msg.Chat.ID, i18n.T(lang, "access.private"), false)
+			return
+		}
+	}
+
 	switch msg.Command() {
 	case "start":
-		text := `👋 Welcome to AgentAI_Tracker!
+		tb.sendMessage(msg.Chat.ID, i18n.T(lang, "start"), false)
 
-I'm an AI-powered financial tracker that can:
-• 📷 Extract transactions from receipt images
-• 📊 Automatically organize data in Google Sheets
-• 🤖 Smart categorization and date handling
+	case "help":
+		tb.sendMessage(msg.Chat.ID, i18n.T(lang, "help"), false)
 
-Just send me:
-• Text: "Add transaction: Indomaret Rp 50,000"
-• Photo: Receipt image
-• Both: Photo with description
+	case "lang":
+		// The language is per chat, so in groups only writers may change it
+		role, ok := tb.role(msg.From)
+		if !ok || (!msg.Chat.IsPrivate() && !role.CanWrite()) {
+			tb.sendMessage(msg.Chat.ID, i18n.T(lang, "access.denied"), false)
+			return
+		}
 
-Let's get started! Send me a receipt or transaction details.`
-		tb.sendMessage(msg.Chat.ID, text, false)
+		arg := msg.CommandArguments()
+		if arg == "" {
+			tb.sendMessage(msg.Chat.ID, i18n.T(lang, "lang.current", lang.Name()), false)
+			return
+		}
 
-	case "help":
-		text := `🤔 How to use AgentAI_Tracker:
+		newLang, ok := i18n.Parse(arg)
+		if !ok {
+			tb.sendMessage(msg.Chat.ID, i18n.T(lang, "lang.unknown", arg), false)
+			return
+		}
+		if err := tb.runner.SetLanguage(tb.ctx, msg.Chat.ID, newLang); err != nil {
+			tb.runner.logger.LogError(ErrorLog{
+				ChatID:    msg.Chat.ID,
+				UserID:    fmt.Sprintf("tg_%d", msg.Chat.ID),
+				Component: "set_language",
+				Error:     err.Error(),
+			})
+			tb.sendMessage(msg.Chat.ID, i18n.T(lang, "lang.failed", err), false)
+			return
+		}
+		tb.sendMessage(msg.Chat.ID, i18n.T(newLang, "lang.changed", newLang.Name()), false)
+
+	case "connect":
+		tb.handleConnect(msg, lang)
+
+	case "invite":
+		tb.handleInvite(msg, lang)
+
+	case "join":
+		tb.handleJoin(msg, lang)
 
-**Text Input:**
-"Indomaret 15 Dec Rp 50,000"
-"Add transaction from Alfamart"
+	case "revoke":
+		tb.handleRevoke(msg, lang)
 
-**Image Input:**
-Just send a photo of your receipt
+	case "users":
+		tb.handleUsers(msg, lang)
 
-**Combined:**
-Send photo with caption describing the transaction
+	case "today", "week", "month", "last", "sheets", "undo", "export", "reset":
+		tb.handleLedgerCommand(msg, lang)
 
-The bot will:
-1. Extract transaction details
-2. Show you what was found
-3. Ask for confirmation
-4. Save to Google Sheets
+	case "digest":
+		tb.handleDigest(msg, lang)
 
-Need help? Just ask me anything!`
-		tb.sendMessage(msg.Chat.ID, text, false)
+	case "bills":
+		tb.handleBills(msg, lang)
 	}
 }
 
-func (tb *TelegramBot) handleMessage(msg *tgbotapi.Message) {
+// handleConnect: /connect <spreadsheet link or ID> routes this chat's
+// transactions to the user's own spreadsheet. In groups only owners may
+// change where the shared ledger goes.
+func (tb *TelegramBot) handleConnect(msg *tgbotapi.Message, lang i18n.Lang) {
 	chatID := msg.Chat.ID
 	userID := fmt.Sprintf("tg_%d", chatID)
 
-	// Extract text and photo
-	text := msg.Text
-	if msg.Caption != "" {
-		text = msg.Caption
+	role, ok := tb.role(msg.From)
+	if !ok || !role.CanWrite() || (!msg.Chat.IsPrivate() && role != RoleOwner) {
+		tb.sendMessage(chatID, i18n.T(lang, "access.denied"), false)
+		return
+	}
+
+	email := tools.ServiceAccountEmail()
+	if email == "" {
+		email = i18n.T(lang, "connect.account")
+	}
+
+	arg := msg.CommandArguments()
+	if arg == "" {
+		current := i18n.T(lang, "connect.default")
+		if id, ok := tools.ConnectedSpreadsheet(userID); ok {
+			current = id
+		}
+		tb.sendMessage(chatID, i18n.T(lang, "connect.usage", current, email), false)
+		return
+	}
+
+	// Opening the spreadsheet is a Sheets API call; keep it off the update loop
+	job := func() {
+		id, err := tools.ConnectSpreadsheet(tb.ctx, userID, arg, role == RoleOwner)
+		switch {
+		case errors.Is(err, tools.ErrSpreadsheetInUse):
+			tb.sendMessage(chatID, i18n.T(lang, "connect.taken"), false)
+		case errors.Is(err, tools.ErrDefaultSpreadsheet):
+			tb.sendMessage(chatID, i18n.T(lang, "connect.shared"), false)
+		case err != nil:
+			tb.runner.logger.LogError(ErrorLog{
+				ChatID:    chatID,
+				UserID:    userID,
+				Component: "sheet_connect",
+				Error:     err.Error(),
+				Details:   fmt.Sprintf("Spreadsheet: %s", arg),
+			})
+			tb.sendMessage(chatID, i18n.T(lang, "connect.failed", err, email), false)
+		default:
+			tb.sendMessage(chatID, i18n.T(lang, "connect.ok", id), false)
+		}
+	}
+	if !tb.queue.Submit(chatID, job) {
+		tb.sendMessage(chatID, i18n.T(lang, "busy"), false)
+	}
+}
+
+// language resolves the chat language, detecting it from the sender's
+// Telegram settings on first contact. Strangers only get the detected
+// language, so replying to them never creates a session.
+func (tb *TelegramBot) language(chatID int64, from *tgbotapi.User) i18n.Lang {
+	code := ""
+	if from != nil {
+		code = from.LanguageCode
+	}
+	if _, ok := tb.role(from); !ok {
+		return i18n.Detect(code)
+	}
+	return tb.runner.Language(tb.ctx, chatID, code)
+}
+
+func (tb *TelegramBot) handleMessage(msg *tgbotapi.Message, role Role) {
+	tb.handleMessages([]*tgbotapi.Message{msg}, role)
+}
+
+// handleMessages runs one agent turn for one message or a whole album: all
+// photos plus the caption (Telegram puts it on one of the album messages).
+func (tb *TelegramBot) handleMessages(msgs []*tgbotapi.Message, role Role) {
+	chatID := msgs[0].Chat.ID
+	userID := fmt.Sprintf("tg_%d", chatID)
+	lang := tb.language(chatID, msgs[0].From)
+
+	// Extract text and photos
+	var text string
+	for _, msg := range msgs {
+		if msg.Caption != "" {
+			text = msg.Caption
+			break
+		}
+		if msg.Text != "" {
+			text = msg.Text
+			break
+		}
 	}
 
 	// Log user message
@@ -140,15 +466,30 @@ func (tb *TelegramBot) handleMessage(msg *tgbotapi.Message) {
 		tb.runner.logger.LogUserMessage(chatID, userID, text)
 	}
 
-	var photoPath string
-	var err error
+	// Several members share a group's session, so the agent is told who
+	// is speaking
+	author := senderName(msgs[0].From)
+	if isGroup(msgs[0].Chat) {
+		text = strings.TrimSpace(fmt.Sprintf("[%s] %s", author, tb.stripMention(text)))
+	}
+
+	var attachments []Attachment
+	defer func() {
+		for _, att := range attachments {
+			os.Remove(att.Path) // Cleanup after processing
+		}
+	}()
+
+	// Handle photos
+	for _, msg := range msgs {
+		if len(msg.Photo) == 0 {
+			continue
+		}
 
-	// Handle photo
-	if msg.Photo != nil && len(msg.Photo) > 0 {
 		// Get largest photo
 		photo := msg.Photo[len(msg.Photo)-1]
 
-		photoPath, err = tb.downloadPhoto(photo.FileID)
+		photoPath, mimeType, err := tb.downloadPhoto(tb.ctx, photo.FileID)
 		if err != nil {
 			// Log error
 			tb.runner.logger.LogError(ErrorLog{
@@ -159,28 +500,136 @@ func (tb *TelegramBot) handleMessage(msg *tgbotapi.Message) {
 				Details:   fmt.Sprintf("FileID: %s", photo.FileID),
 			})
 
-			tb.sendMessage(chatID, fmt.Sprintf("❌ Failed to download photo: %v", err), false)
+			tb.sendMessage(chatID, i18n.T(lang, "error.photo", err), false)
 			return
 		}
 
 		// Log successful photo upload
 		tb.runner.logger.LogPhotoUpload(chatID, userID, photoPath)
+		attachments = append(attachments, Attachment{Path: photoPath, MimeType: mimeType})
+	}
+
+	// Handle images and PDFs sent as files
+	for _, msg := range msgs {
+		if msg.Document == nil {
+			continue
+		}
 
-		defer os.Remove(photoPath) // Cleanup after processing
+		docPath, err := tb.downloadDocument(tb.ctx, msg.Document)
+		if err != nil {
+			tb.runner.logger.LogError(ErrorLog{
+				ChatID:    chatID,
+				UserID:    userID,
+				Component: "document_download",
+				Error:     err.Error(),
+				Details:   fmt.Sprintf("FileID: %s, MimeType: %s", msg.Document.FileID, msg.Document.MimeType),
+			})
+
+			if errors.Is(err, errUnsupportedFile) {
+				tb.sendMessage(chatID, i18n.T(lang, "error.file_type", msg.Document.FileName), false)
+			} else {
+				tb.sendMessage(chatID, i18n.T(lang, "error.file", err), false)
+			}
+			return
+		}
+
+		tb.runner.logger.LogPhotoUpload(chatID, userID, docPath)
+		attachments = append(attachments, Attachment{Path: docPath, MimeType: msg.Document.MimeType})
+	}
+
+	// Handle voice notes and audio files
+	for _, msg := range msgs {
+		att, err := tb.downloadAudio(tb.ctx, msg)
+		if err != nil {
+			tb.runner.logger.LogError(ErrorLog{
+				ChatID:    chatID,
+				UserID:    userID,
+				Component: "voice_download",
+				Error:     err.Error(),
+			})
+
+			if errors.Is(err, errUnsupportedFile) {
+				tb.sendMessage(chatID, i18n.T(lang, "error.audio", err), false)
+			} else {
+				tb.sendMessage(chatID, i18n.T(lang, "error.file", err), false)
+			}
+			return
+		}
+		if att == nil {
+			continue
+		}
+		tb.runner.logger.LogPhotoUpload(chatID, userID, att.Path)
+
+		if tb.transcriber == nil {
+			attachments = append(attachments, *att)
+			continue
+		}
+
+		// Transcribe locally and send the text instead of the audio
+		transcript, err := tb.transcriber.Transcribe(tb.ctx, att.Path, att.MimeType)
+		os.Remove(att.Path)
+		if err != nil {
+			tb.runner.logger.LogError(ErrorLog{
+				ChatID:    chatID,
+				UserID:    userID,
+				Component: "voice_transcribe",
+				Error:     err.Error(),
+			})
+			tb.sendMessage(chatID, i18n.T(lang, "error.transcribe", err), false)
+			return
+		}
+
+		tb.runner.logger.LogUserMessage(chatID, userID, transcript)
+		text = strings.TrimSpace(text + "\n[voice note transcript] " + transcript)
 	}
 
 	// Process with runner
-	result, err := tb.runner.ProcessMessage(tb.ctx, chatID, text, photoPath)
+	// The role limits the tools the agent can use (read-only: queries only)
+	tb.runTurn(chatID, userID, lang, "agent_processing", func(onStage StageFunc) (*ProcessResult, error) {
+		ctx := withGroupAuthor(WithRole(WithSender(tb.ctx, msgs[0].From.ID), role), msgs[0].Chat, msgs[0].From)
+		return tb.runner.ProcessMessage(ctx, chatID, text, attachments, onStage)
+	})
+}
+
+// runTurn runs the agent with a live progress message and delivers the
+// result into it.
+func (tb *TelegramBot) runTurn(chatID int64, userID string, lang i18n.Lang, component string, run func(StageFunc) (*ProcessResult, error)) {
+	progress := tb.newProgress(chatID)
+
+	var result *ProcessResult
+	var err error
+	if tb.ctx.Err() == nil {
+		result, err = run(progress.Update)
+	}
+	if tb.ctx.Err() != nil {
+		// Shutdown cancelled the run; whatever it saved stays saved
+		tb.interrupted.Add(1)
+		log.Printf("⚠️ Chat %d: turn interrupted by shutdown", chatID)
+		progress.Finish(i18n.T(lang, "shutdown.interrupted"), nil)
+		return
+	}
+
+	var limited *RateLimitError
+	if errors.As(err, &limited) {
+		log.Printf("🚦 Chat %d: %v", chatID, limited)
+		seconds := int(limited.RetryAfter.Round(time.Second).Seconds())
+		if limited.Global {
+			progress.Finish(i18n.T(lang, "rate.global", seconds), nil)
+		} else {
+			progress.Finish(i18n.T(lang, "rate.user", seconds), nil)
+		}
+		return
+	}
 	if err != nil {
 		// Log processing error
 		tb.runner.logger.LogError(ErrorLog{
 			ChatID:    chatID,
 			UserID:    userID,
-			Component: "agent_processing",
+			Component: component,
 			Error:     err.Error(),
 		})
 
-		tb.sendMessage(chatID, fmt.Sprintf("❌ Processing error: %v", err), false)
+		progress.Finish(i18n.T(lang, "error.process", err), nil)
 		return
 	}
 
@@ -193,60 +642,214 @@ func (tb *TelegramBot) handleMessage(msg *tgbotapi.Message) {
 			Error:     result.Error.Error(),
 		})
 
-		tb.sendMessage(chatID, fmt.Sprintf("❌ Agent error: %v", result.Error), false)
+		progress.Finish(i18n.T(lang, "error.agent", result.Error), nil)
 		return
 	}
 
-	// Send messages progressively and track tool messages for deletion
-	var deleteIDs []int
-	var finalResponses []string
+	tb.deliver(chatID, userID, lang, result, progress)
+}
 
+// deliver replaces the progress message with the agent's answer. A single
+// new action puts its buttons under the answer (the extraction preview);
+// several get one confirmation message each.
+func (tb *TelegramBot) deliver(chatID int64, userID string, lang i18n.Lang, result *ProcessResult, progress *progressMessage) {
+	var texts []string
 	for _, stage := range result.Stages {
-		msgID := tb.sendMessage(chatID, stage.Content, tb.config.EnableMarkdown)
-		if stage.ShouldDelete {
-			deleteIDs = append(deleteIDs, msgID)
-		} else if stage.Type == "text" {
-			// Collect final responses for logging
-			finalResponses = append(finalResponses, stage.Content)
+		if stage.Type == "text" {
+			texts = append(texts, stage.Content)
+			tb.runner.logger.LogAgentResponse(chatID, userID, stage.Content)
 		}
 	}
 
-	// Log agent responses
-	if len(finalResponses) > 0 {
-		for _, resp := range finalResponses {
-			tb.runner.logger.LogAgentResponse(chatID, userID, resp)
+	var markup *tgbotapi.InlineKeyboardMarkup
+	if len(result.Pending) == 1 {
+		keyboard := confirmationKeyboard(lang, result.Pending[0])
+		markup = &keyboard
+	}
+	progress.Finish(strings.Join(texts, "\n\n"), markup)
+
+	// Mutations wait for an explicit button press
+	if len(result.Pending) > 1 {
+		for _, action := range result.Pending {
+			tb.sendConfirmation(chatID, lang, i18n.T(lang, "confirm.prompt", action.Summary), action)
 		}
 	}
+}
 
-	// Cleanup tool messages after brief delay
-	if len(deleteIDs) > 0 {
-		time.Sleep(tb.config.DeleteDelay)
-		for _, msgID := range deleteIDs {
-			deleteConfig := tgbotapi.DeleteMessageConfig{
-				ChatID:    chatID,
-				MessageID: msgID,
-			}
-			tb.bot.Request(deleteConfig)
+// sendConfirmation sends text with Confirm / Edit / Cancel buttons for action.
+func (tb *TelegramBot) sendConfirmation(chatID int64, lang i18n.Lang, text string, action hitl.PendingAction) int {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = confirmationKeyboard(lang, action)
+	return tb.send(msg, tb.config.EnableMarkdown)
+}
+
+func confirmationKeyboard(lang i18n.Lang, action hitl.PendingAction) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "confirm.ok"), callbackData(hitl.Approve, action.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "confirm.edit"), callbackData(hitl.Edit, action.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "confirm.cancel"), callbackData(hitl.Cancel, action.ID)),
+		),
+	)
+}
+
+// callbackData encodes a button as "hitl:<decision>:<action id>".
+func callbackData(decision hitl.Decision, actionID string) string {
+	return fmt.Sprintf("hitl:%s:%s", decision, actionID)
+}
+
+// handleCallback applies a confirmation button, marks the original message
+// with the final state and resumes the agent so it reacts to the decision.
+func (tb *TelegramBot) handleCallback(cb *tgbotapi.CallbackQuery, role Role) {
+	if cb.Message == nil {
+		return
+	}
+	chatID := cb.Message.Chat.ID
+	userID := fmt.Sprintf("tg_%d", chatID)
+	lang := tb.language(chatID, cb.From)
+
+	parts := strings.SplitN(cb.Data, ":", 3)
+	decision, ok := hitl.Decision(""), false
+	if len(parts) == 3 && parts[0] == "hitl" {
+		decision, ok = hitl.ParseDecision(parts[1])
+	}
+	if !ok {
+		tb.bot.Request(tgbotapi.NewCallback(cb.ID, ""))
+		return
+	}
+
+	// An owner's alias proposal in a group must not be approved by a member
+	if decision == hitl.Approve && role != RoleOwner && tb.ownerOnly(chatID, parts[2]) {
+		tb.bot.Request(tgbotapi.NewCallback(cb.ID, i18n.T(lang, "notice.denied")))
+		return
+	}
+
+	res, err := tb.runner.ResolveAction(tb.ctx, chatID, parts[2], decision)
+	if err != nil {
+		tb.runner.logger.LogError(ErrorLog{
+			ChatID:    chatID,
+			UserID:    userID,
+			Component: "hitl_resolve",
+			Error:     err.Error(),
+			Details:   fmt.Sprintf("Callback: %s", cb.Data),
+		})
+		tb.bot.Request(tgbotapi.NewCallback(cb.ID, i18n.T(lang, "notice.handled")))
+		tb.editConfirmation(cb.Message, fmt.Sprintf("⚠️ %v", err))
+		return
+	}
+
+	var status, notice string
+	switch {
+	case decision == hitl.Edit:
+		status, notice = i18n.T(lang, "confirm.editing"), i18n.T(lang, "notice.edit")
+	case decision == hitl.Cancel:
+		status, notice = i18n.T(lang, "confirm.cancelled"), i18n.T(lang, "notice.cancelled")
+	case res.Err != nil:
+		status, notice = i18n.T(lang, "confirm.failed", res.Err), i18n.T(lang, "notice.failed")
+	default:
+		status, notice = i18n.T(lang, "confirm.saved", res.Message), i18n.T(lang, "notice.saved")
+	}
+
+	tb.bot.Request(tgbotapi.NewCallback(cb.ID, notice))
+	tb.editConfirmation(cb.Message, status)
+
+	tb.runTurn(chatID, userID, lang, "agent_resume", func(onStage StageFunc) (*ProcessResult, error) {
+		ctx := withGroupAuthor(WithRole(WithSender(tb.ctx, cb.From.ID), role), cb.Message.Chat, cb.From)
+		return tb.runner.Resume(ctx, chatID, onStage)
+	})
+}
+
+// ownerOnly reports whether the pending action runs one of OwnerTools.
+// When the actions cannot be read it fails closed and reports true.
+func (tb *TelegramBot) ownerOnly(chatID int64, actionID string) bool {
+	pending, err := tb.runner.PendingActions(tb.ctx, chatID)
+	if err != nil {
+		tb.runner.logger.LogError(ErrorLog{
+			ChatID:    chatID,
+			UserID:    fmt.Sprintf("tg_%d", chatID),
+			Component: "pending_actions",
+			Error:     err.Error(),
+			Details:   fmt.Sprintf("Action: %s", actionID),
+		})
+		return true
+	}
+	for _, action := range pending {
+		if action.ID == actionID {
+			return OwnerTools[action.Tool]
+		}
+	}
+	return false
+}
+
+// editConfirmation appends status to a confirmation message and removes its
+// buttons. The original formatting is kept through its entities.
+func (tb *TelegramBot) editConfirmation(msg *tgbotapi.Message, status string) {
+	edit := tgbotapi.NewEditMessageText(msg.Chat.ID, msg.MessageID, msg.Text+"\n\n"+status)
+	edit.Entities = msg.Entities
+	if _, err := tb.bot.Send(edit); err != nil {
+		log.Printf("❌ Failed to edit confirmation: %v", err)
+	}
+}
+
+// edit applies a message edit. The text must be a single chunk from
+// tb.chunks; rejected HTML is retried as plain text.
+func (tb *TelegramBot) edit(edit tgbotapi.EditMessageTextConfig, enableMarkdown bool) error {
+	if enableMarkdown {
+		edit.ParseMode = tgbotapi.ModeHTML
+		if _, err := tb.bot.Send(edit); err == nil {
+			return nil
 		}
+		edit.ParseMode = ""
+		edit.Text = plainText(edit.Text)
 	}
+	_, err := tb.bot.Send(edit)
+	return err
 }
 
 func (tb *TelegramBot) sendMessage(chatID int64, text string, enableMarkdown bool) int {
-	msg := tgbotapi.NewMessage(chatID, text)
+	return tb.send(tgbotapi.NewMessage(chatID, text), enableMarkdown)
+}
+
+// send renders msg.Text and sends it as one or more messages. The reply
+// markup goes on the last one, whose ID is returned.
+func (tb *TelegramBot) send(msg tgbotapi.MessageConfig, enableMarkdown bool) int {
+	chunks := tb.chunks(msg.Text, enableMarkdown)
+
+	messageID := 0
+	for i, chunk := range chunks {
+		part := msg
+		part.Text = chunk
+		if i < len(chunks)-1 {
+			part.ReplyMarkup = nil
+		}
+		messageID = tb.sendChunk(part, enableMarkdown)
+	}
+	return messageID
+}
 
+// chunks renders text to Telegram HTML (or keeps it plain) and splits it
+// into messages within Telegram's length limit.
+func (tb *TelegramBot) chunks(text string, enableMarkdown bool) []string {
 	if enableMarkdown {
-		msg.ParseMode = "Markdown"
+		return renderHTML(text)
+	}
+	return splitText(text, maxMessageLength)
+}
 
-		// Try with markdown first
+func (tb *TelegramBot) sendChunk(msg tgbotapi.MessageConfig, enableMarkdown bool) int {
+	if enableMarkdown {
+		msg.ParseMode = tgbotapi.ModeHTML
+
+		// Try with HTML first
 		sent, err := tb.bot.Send(msg)
-		if err != nil {
-			// Fallback to plain text if markdown fails
-			log.Printf("⚠️ Markdown parse failed, falling back to plain text: %v", err)
-			msg.ParseMode = ""
-			sent, _ = tb.bot.Send(msg)
+		if err == nil {
 			return sent.MessageID
 		}
-		return sent.MessageID
+
+		// Fallback to plain text if Telegram rejects the HTML
+		log.Printf("⚠️ HTML parse failed, falling back to plain text: %v", err)
+		msg.ParseMode = ""
+		msg.Text = plainText(msg.Text)
 	}
 
 	sent, err := tb.bot.Send(msg)
@@ -257,7 +860,75 @@ func (tb *TelegramBot) sendMessage(chatID int64, text string, enableMarkdown boo
 	return sent.MessageID
 }
 
-func (tb *TelegramBot) downloadPhoto(fileID string) (string, error) {
+// downloadPhoto saves a photo and returns its path and sniffed type.
+// Telegram recompresses photos to JPEG, but the extension follows the
+// content rather than assuming it.
+func (tb *TelegramBot) downloadPhoto(ctx context.Context, fileID string) (string, string, error) {
+	path, err := tb.downloadFile(ctx, fileID, "tg_photo_", "")
+	if err != nil {
+		return "", "", err
+	}
+
+	mimeType, err := imageprep.SniffFile(path)
+	if err != nil {
+		os.Remove(path)
+		return "", "", err
+	}
+	ext, ok := documentTypes[mimeType]
+	if !ok || ext == ".pdf" {
+		os.Remove(path)
+		return "", "", fmt.Errorf("%w: photo is %s", errUnsupportedFile, mimeType)
+	}
+	if err := os.Rename(path, path+ext); err != nil {
+		os.Remove(path)
+		return "", "", fmt.Errorf("failed to save photo: %w", err)
+	}
+	return path + ext, mimeType, nil
+}
+
+// documentTypes are the file types accepted as receipts, with the
+// extension used for the temp file.
+var documentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// errUnsupportedFile is returned for documents that are not receipts.
+var errUnsupportedFile = errors.New("unsupported file type")
+
+// downloadDocument saves an image or PDF sent as a file. The content is
+// sniffed so a renamed file cannot reach the model with the wrong type.
+func (tb *TelegramBot) downloadDocument(ctx context.Context, doc *tgbotapi.Document) (string, error) {
+	ext, ok := documentTypes[doc.MimeType]
+	if !ok {
+		return "", fmt.Errorf("%w: %s (%s)", errUnsupportedFile, doc.FileName, doc.MimeType)
+	}
+	if int64(doc.FileSize) > tb.config.MaxPhotoSize {
+		return "", fmt.Errorf("file too large: %d bytes (max: %d)", doc.FileSize, tb.config.MaxPhotoSize)
+	}
+
+	path, err := tb.downloadFile(ctx, doc.FileID, "tg_doc_", ext)
+	if err != nil {
+		return "", err
+	}
+
+	sniffed, err := imageprep.SniffFile(path)
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	if sniffed != doc.MimeType {
+		os.Remove(path)
+		return "", fmt.Errorf("%w: %s is %s, not %s", errUnsupportedFile, doc.FileName, sniffed, doc.MimeType)
+	}
+	return path, nil
+}
+
+// downloadFile saves a Telegram file to a temp file. ctx cancels the
+// download, e.g. on shutdown.
+func (tb *TelegramBot) downloadFile(ctx context.Context, fileID, prefix, ext string) (string, error) {
 	// Get file from Telegram
 	file, err := tb.bot.GetFile(tgbotapi.FileConfig{FileID: fileID})
 	if err != nil {
@@ -266,12 +937,15 @@ func (tb *TelegramBot) downloadPhoto(fileID string) (string, error) {
 
 	// Check file size
 	if file.FileSize > int(tb.config.MaxPhotoSize) {
-		return "", fmt.Errorf("photo too large: %d bytes (max: %d)", file.FileSize, tb.config.MaxPhotoSize)
+		return "", fmt.Errorf("file too large: %d bytes (max: %d)", file.FileSize, tb.config.MaxPhotoSize)
 	}
 
 	// Download file
-	url := file.Link(tb.bot.Token)
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, file.Link(tb.bot.Token), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create download request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to download: %w", err)
 	}
@@ -279,7 +953,7 @@ func (tb *TelegramBot) downloadPhoto(fileID string) (string, error) {
 
 	// Generate simple unique filename without using fileID
 	timestamp := time.Now().UnixNano()
-	tempPath := filepath.Join(tb.config.PhotoTempDir, fmt.Sprintf("tg_photo_%d.jpg", timestamp))
+	tempPath := filepath.Join(tb.config.PhotoTempDir, fmt.Sprintf("%s%d%s", prefix, timestamp, ext))
 
 	tempFile, err := os.Create(tempPath)
 	if err != nil {
@@ -290,7 +964,7 @@ func (tb *TelegramBot) downloadPhoto(fileID string) (string, error) {
 	_, err = io.Copy(tempFile, resp.Body)
 	if err != nil {
 		os.Remove(tempPath) // Cleanup on error
-		return "", fmt.Errorf("failed to save photo: %w", err)
+		return "", fmt.Errorf("failed to save file: %w", err)
 	}
 
 	return tempPath, nil
@@ -0,0 +1,118 @@
+// go-agent-tracker/internal/telegram/bot_access.go
+package telegram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"finagent/internal/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// role looks up the sender in the allowlist.
+func (tb *TelegramBot) role(from *tgbotapi.User) (Role, bool) {
+	if from == nil {
+		return "", false
+	}
+	return tb.access.Role(from.ID)
+}
+
+// handleInvite: /invite [member|readonly] (owners only)
+func (tb *TelegramBot) handleInvite(msg *tgbotapi.Message, lang i18n.Lang) {
+	role := RoleMember
+	if arg := msg.CommandArguments(); arg != "" {
+		var ok bool
+		if role, ok = ParseRole(arg); !ok {
+			tb.sendMessage(msg.Chat.ID, i18n.T(lang, "invite.usage"), false)
+			return
+		}
+	}
+
+	invite, err := tb.access.CreateInvite(msg.From.ID, role)
+	if err != nil {
+		tb.sendMessage(msg.Chat.ID, i18n.T(lang, "access.failed", err), false)
+		return
+	}
+
+	tb.sendMessage(msg.Chat.ID, i18n.T(lang, "invite.created",
+		invite.Role, invite.Code, invite.ExpiresAt.Format("2006-01-02 15:04")), false)
+}
+
+// handleJoin: /join CODE
+func (tb *TelegramBot) handleJoin(msg *tgbotapi.Message, lang i18n.Lang) {
+	code := msg.CommandArguments()
+	if code == "" {
+		tb.sendMessage(msg.Chat.ID, i18n.T(lang, "join.usage"), false)
+		return
+	}
+
+	role, err := tb.access.Join(msg.From.ID, displayName(msg.From), code)
+	if err != nil {
+		tb.runner.logger.LogError(ErrorLog{
+			ChatID:    msg.Chat.ID,
+			UserID:    fmt.Sprintf("tg_%d", msg.Chat.ID),
+			Component: "access_join",
+			Error:     err.Error(),
+		})
+		tb.sendMessage(msg.Chat.ID, i18n.T(lang, "access.failed", err), false)
+		return
+	}
+
+	tb.sendMessage(msg.Chat.ID, i18n.T(lang, "join.ok", role), false)
+}
+
+// handleRevoke: /revoke USER_ID (owners only)
+func (tb *TelegramBot) handleRevoke(msg *tgbotapi.Message, lang i18n.Lang) {
+	userID, err := strconv.ParseInt(strings.TrimSpace(msg.CommandArguments()), 10, 64)
+	if err != nil {
+		tb.sendMessage(msg.Chat.ID, i18n.T(lang, "revoke.usage"), false)
+		return
+	}
+
+	if err := tb.access.Revoke(msg.From.ID, userID); err != nil {
+		tb.sendMessage(msg.Chat.ID, i18n.T(lang, "access.failed", err), false)
+		return
+	}
+	tb.sendMessage(msg.Chat.ID, i18n.T(lang, "revoke.ok", userID), false)
+}
+
+// handleUsers: /users (owners only)
+func (tb *TelegramBot) handleUsers(msg *tgbotapi.Message, lang i18n.Lang) {
+	if role, _ := tb.role(msg.From); role != RoleOwner {
+		tb.sendMessage(msg.Chat.ID, i18n.T(lang, "access.owner_only"), false)
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, "users.title"))
+	for _, m := range tb.access.Members() {
+		fmt.Fprintf(&sb, "\n• %d %s (%s)", m.ID, m.Name, m.Role)
+	}
+	tb.sendMessage(msg.Chat.ID, sb.String(), false)
+}
+
+func displayName(u *tgbotapi.User) string {
+	if u.UserName != "" {
+		return "@" + u.UserName
+	}
+	return strings.TrimSpace(u.FirstName + " " + u.LastName)
+}
+
+// ParseUserIDs parses a comma separated list of Telegram user IDs.
+func ParseUserIDs(s string) ([]int64, error) {
+	var ids []int64
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user id %q: %w", field, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
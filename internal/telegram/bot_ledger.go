@@ -0,0 +1,384 @@
+// go-agent-tracker/internal/telegram/bot_ledger.go
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"finagent/internal/agent/tools"
+	"finagent/internal/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Ledger commands answer common questions straight from the spreadsheet,
+// without a model call.
+
+const (
+	defaultLastCount = 5
+	maxLastCount     = 30
+)
+
+// ledgerCommands are registered with Telegram together with the basic
+// commands; the description is the i18n key "cmd.<name>".
+var ledgerCommands = []string{"today", "week", "month", "last", "sheets", "undo", "export", "reset"}
+
+// registerCommands publishes the command menu in every supported language.
+func (tb *TelegramBot) registerCommands() {
+	names := append([]string{"help"}, ledgerCommands...)
+	names = append(names, "digest", "bills", "connect", "lang")
+
+	for _, lang := range []i18n.Lang{i18n.English, i18n.Indonesian} {
+		commands := make([]tgbotapi.BotCommand, len(names))
+		for i, name := range names {
+			commands[i] = tgbotapi.BotCommand{Command: name, Description: i18n.T(lang, "cmd."+name)}
+		}
+
+		cfg := tgbotapi.NewSetMyCommands(commands...)
+		if lang != i18n.English {
+			cfg = tgbotapi.NewSetMyCommandsWithScopeAndLanguage(tgbotapi.NewBotCommandScopeDefault(), string(lang), commands...)
+		}
+		if _, err := tb.bot.Request(cfg); err != nil {
+			log.Printf("⚠️ Failed to register commands (%s): %v", lang, err)
+		}
+	}
+}
+
+// handleLedgerCommand runs a ledger command in the chat queue, so it never
+// races an agent turn that is writing to the same spreadsheet.
+func (tb *TelegramBot) handleLedgerCommand(msg *tgbotapi.Message, lang i18n.Lang) {
+	chatID := msg.Chat.ID
+
+	role, ok := tb.role(msg.From)
+	if !ok || ((msg.Command() == "undo" || msg.Command() == "reset") && !role.CanWrite()) {
+		tb.sendMessage(chatID, i18n.T(lang, "access.denied"), false)
+		return
+	}
+
+	job := func() {
+		switch cmd := msg.Command(); cmd {
+		case "today", "week", "month":
+			tb.handleSummary(chatID, lang, cmd)
+		case "last":
+			tb.handleLast(chatID, lang, msg.CommandArguments())
+		case "sheets":
+			tb.handleSheets(chatID, lang)
+		case "undo":
+			tb.handleUndo(chatID, lang)
+		case "export":
+			tb.handleExport(chatID, lang, msg.CommandArguments())
+		case "reset":
+			tb.handleReset(chatID, lang)
+		}
+	}
+	if !tb.queue.Submit(chatID, job) {
+		tb.sendMessage(chatID, i18n.T(lang, "busy"), false)
+	}
+}
+
+// ledgerContext routes the tools to the chat's spreadsheet.
+func (tb *TelegramBot) ledgerContext(chatID int64) context.Context {
+	return tools.WithUserID(tb.ctx, fmt.Sprintf("tg_%d", chatID))
+}
+
+func (tb *TelegramBot) ledgerError(chatID int64, lang i18n.Lang, component string, err error) {
+	tb.runner.logger.LogError(ErrorLog{
+		ChatID:    chatID,
+		UserID:    fmt.Sprintf("tg_%d", chatID),
+		Component: component,
+		Error:     err.Error(),
+	})
+	tb.sendMessage(chatID, i18n.T(lang, "ledger.failed", err), false)
+}
+
+// handleSummary: /today, /week (since Monday), /month
+func (tb *TelegramBot) handleSummary(chatID int64, lang i18n.Lang, period string) {
+	txs, err := tools.Transactions(tb.ledgerContext(chatID))
+	if err != nil {
+		tb.ledgerError(chatID, lang, "ledger_summary", err)
+		return
+	}
+
+	from, to := periodRange(period, time.Now())
+	text := summaryText(lang, "📊 "+i18n.T(lang, "summary."+period), tools.Summarize(txs, from, to))
+	tb.sendMessage(chatID, text, tb.config.EnableMarkdown)
+}
+
+// summaryText renders a summary: the total, then tables by category and
+// by merchant (top 5).
+func summaryText(lang i18n.Lang, title string, summary tools.Summary) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("**%s** (%s)\n", title, dateRange(summary.From, summary.To)))
+	if summary.Count == 0 {
+		sb.WriteString(i18n.T(lang, "summary.empty"))
+		return sb.String()
+	}
+	sb.WriteString(i18n.T(lang, "summary.total", tools.FormatRupiah(summary.Total), summary.Count, summary.Receipts))
+
+	sb.WriteString(fmt.Sprintf("\n\n| %s | %s |\n|---|---:|\n", i18n.T(lang, "col.category"), i18n.T(lang, "col.amount")))
+	for _, total := range summary.Categories {
+		sb.WriteString(fmt.Sprintf("| %s | %s |\n", tableCell(total.Name), tools.FormatRupiah(total.Amount)))
+	}
+
+	sb.WriteString(fmt.Sprintf("\n| %s | %s |\n|---|---:|\n", i18n.T(lang, "col.merchant"), i18n.T(lang, "col.amount")))
+	for i, total := range summary.Merchants {
+		if i == 5 {
+			break
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s |\n", tableCell(total.Name), tools.FormatRupiah(total.Amount)))
+	}
+	return sb.String()
+}
+
+// handleLast: /last [N] shows the most recently added transactions.
+func (tb *TelegramBot) handleLast(chatID int64, lang i18n.Lang, arg string) {
+	n := defaultLastCount
+	if arg = strings.TrimSpace(arg); arg != "" {
+		parsed, err := strconv.Atoi(arg)
+		if err != nil || parsed < 1 {
+			tb.sendMessage(chatID, i18n.T(lang, "last.usage", maxLastCount), false)
+			return
+		}
+		n = min(parsed, maxLastCount)
+	}
+
+	txs, err := tools.LastTransactions(tb.ledgerContext(chatID), n)
+	if err != nil {
+		tb.ledgerError(chatID, lang, "ledger_last", err)
+		return
+	}
+	if len(txs) == 0 {
+		tb.sendMessage(chatID, i18n.T(lang, "ledger.empty"), false)
+		return
+	}
+
+	tb.sendMessage(chatID, i18n.T(lang, "last.title", len(txs))+"\n\n"+transactionTable(lang, txs), tb.config.EnableMarkdown)
+}
+
+// handleSheets: /sheets lists the sheets with their transaction counts.
+func (tb *TelegramBot) handleSheets(chatID int64, lang i18n.Lang) {
+	ctx := tb.ledgerContext(chatID)
+
+	sheets, err := tools.ListSheetsWithInfo(ctx)
+	if err != nil {
+		tb.ledgerError(chatID, lang, "ledger_sheets", err)
+		return
+	}
+	txs, err := tools.Transactions(ctx)
+	if err != nil {
+		tb.ledgerError(chatID, lang, "ledger_sheets", err)
+		return
+	}
+
+	counts := make(map[string]int)
+	totals := make(map[string]float64)
+	for _, tx := range txs {
+		counts[tx.Sheet]++
+		totals[tx.Sheet] += tx.Amount
+	}
+
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, "sheets.title", len(sheets)))
+	sb.WriteString(fmt.Sprintf("\n\n| %s | %s | %s |\n|---|---:|---:|\n", i18n.T(lang, "col.sheet"), i18n.T(lang, "col.rows"), i18n.T(lang, "col.amount")))
+	for _, sheet := range sheets {
+		sb.WriteString(fmt.Sprintf("| %s | %d | %s |\n", tableCell(sheet.Title), counts[sheet.Title], tools.FormatRupiah(totals[sheet.Title])))
+	}
+
+	tb.sendMessage(chatID, sb.String(), tb.config.EnableMarkdown)
+}
+
+// handleUndo: /undo shows the rows of the last receipt added in this chat
+// with Delete / Keep buttons; nothing is deleted until Delete is pressed.
+func (tb *TelegramBot) handleUndo(chatID int64, lang i18n.Lang) {
+	last, rows, err := tools.LastReceipt(tb.ledgerContext(chatID))
+	if errors.Is(err, tools.ErrNothingToUndo) {
+		// Appends are only tracked in memory, so a restart forgets them
+		tb.sendMessage(chatID, i18n.T(lang, "undo.none"), false)
+		return
+	}
+	if err != nil {
+		tb.ledgerError(chatID, lang, "ledger_undo", err)
+		return
+	}
+
+	var total float64
+	for _, tx := range rows {
+		total += tx.Amount
+	}
+
+	text := i18n.T(lang, "undo.confirm", len(rows), last.ReceiptID, last.Sheet, tools.FormatRupiah(total))
+	msg := tgbotapi.NewMessage(chatID, text+"\n\n"+transactionTable(lang, rows))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "undo.delete"), undoCallbackData("ok", last.Seq)),
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "undo.keep"), undoCallbackData("no", last.Seq)),
+		),
+	)
+	tb.send(msg, tb.config.EnableMarkdown)
+}
+
+// undoCallbackData encodes an undo button as "undo:<ok|no>:<seq>"; seq
+// identifies the append that was shown.
+func undoCallbackData(action string, seq int64) string {
+	return fmt.Sprintf("undo:%s:%d", action, seq)
+}
+
+// handleUndoCallback deletes the rows shown by /undo, unless another
+// receipt was added since.
+func (tb *TelegramBot) handleUndoCallback(cb *tgbotapi.CallbackQuery) {
+	chatID := cb.Message.Chat.ID
+	lang := tb.language(chatID, cb.From)
+
+	parts := strings.Split(cb.Data, ":")
+	var seq int64
+	if len(parts) == 3 {
+		seq, _ = strconv.ParseInt(parts[2], 10, 64)
+	}
+	if seq == 0 {
+		tb.bot.Request(tgbotapi.NewCallback(cb.ID, ""))
+		return
+	}
+
+	if parts[1] != "ok" {
+		tb.bot.Request(tgbotapi.NewCallback(cb.ID, i18n.T(lang, "notice.cancelled")))
+		tb.editConfirmation(cb.Message, i18n.T(lang, "undo.kept"))
+		return
+	}
+
+	removed, err := tools.UndoLastReceipt(tb.ledgerContext(chatID), seq)
+	if err != nil {
+		tb.runner.logger.LogError(ErrorLog{
+			ChatID:    chatID,
+			UserID:    fmt.Sprintf("tg_%d", chatID),
+			Component: "ledger_undo",
+			Error:     err.Error(),
+			Details:   fmt.Sprintf("Callback: %s", cb.Data),
+		})
+		tb.bot.Request(tgbotapi.NewCallback(cb.ID, i18n.T(lang, "notice.failed")))
+		tb.editConfirmation(cb.Message, i18n.T(lang, "confirm.failed", err))
+		return
+	}
+
+	var total float64
+	for _, tx := range removed {
+		total += tx.Amount
+	}
+	last := removed[len(removed)-1]
+
+	log.Printf("↩️ Chat %d: removed %d rows of %s from %s", chatID, len(removed), last.ReceiptID, last.Sheet)
+	tb.bot.Request(tgbotapi.NewCallback(cb.ID, i18n.T(lang, "notice.saved")))
+	tb.editConfirmation(cb.Message, i18n.T(lang, "undo.ok", len(removed), last.ReceiptID, last.Sheet, tools.FormatRupiah(total)))
+}
+
+// handleExport: /export [today|week|month] sends the transactions as CSV.
+func (tb *TelegramBot) handleExport(chatID int64, lang i18n.Lang, arg string) {
+	period := strings.ToLower(strings.TrimSpace(arg))
+	if period != "" && period != "today" && period != "week" && period != "month" {
+		tb.sendMessage(chatID, i18n.T(lang, "export.usage"), false)
+		return
+	}
+
+	txs, err := tools.Transactions(tb.ledgerContext(chatID))
+	if err != nil {
+		tb.ledgerError(chatID, lang, "ledger_export", err)
+		return
+	}
+
+	now := time.Now()
+	if period != "" {
+		from, to := periodRange(period, now)
+		var filtered []tools.Transaction
+		for _, tx := range txs {
+			if !tx.Date.Before(from) && tx.Date.Before(to) {
+				filtered = append(filtered, tx)
+			}
+		}
+		txs = filtered
+	}
+	if len(txs) == 0 {
+		tb.sendMessage(chatID, i18n.T(lang, "ledger.empty"), false)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := tools.WriteCSV(&buf, txs); err != nil {
+		tb.ledgerError(chatID, lang, "ledger_export", err)
+		return
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("transactions_%s.csv", now.Format("20060102")),
+		Bytes: buf.Bytes(),
+	})
+	doc.Caption = i18n.T(lang, "export.caption", len(txs))
+	if _, err := tb.bot.Send(doc); err != nil {
+		tb.ledgerError(chatID, lang, "ledger_export", err)
+	}
+}
+
+// handleReset: /reset starts a new conversation with the agent.
+func (tb *TelegramBot) handleReset(chatID int64, lang i18n.Lang) {
+	pending, err := tb.runner.ResetSession(tb.ctx, chatID)
+	if err != nil {
+		tb.ledgerError(chatID, lang, "session_reset", err)
+		return
+	}
+
+	text := i18n.T(lang, "reset.ok")
+	if pending > 0 {
+		text += "\n\n" + i18n.T(lang, "reset.pending", pending)
+	}
+	tb.sendMessage(chatID, text, false)
+}
+
+// periodRange returns [from, to) for "today", "week" (since Monday) and
+// "month", ending at the end of today.
+func periodRange(period string, now time.Time) (time.Time, time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	to := today.AddDate(0, 0, 1)
+
+	switch period {
+	case "week":
+		return today.AddDate(0, 0, -(int(today.Weekday())+6)%7), to
+	case "month":
+		return today.AddDate(0, 0, 1-today.Day()), to
+	}
+	return today, to
+}
+
+func dateRange(from, to time.Time) string {
+	last := to.AddDate(0, 0, -1)
+	if from.Equal(last) {
+		return from.Format("2 Jan 2006")
+	}
+	return from.Format("2 Jan") + " – " + last.Format("2 Jan 2006")
+}
+
+func transactionTable(lang i18n.Lang, txs []tools.Transaction) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n|---|---|---|---:|\n",
+		i18n.T(lang, "col.date"), i18n.T(lang, "col.item"), i18n.T(lang, "col.merchant"), i18n.T(lang, "col.amount")))
+	for _, tx := range txs {
+		date := "-"
+		if !tx.Date.IsZero() {
+			date = tx.Date.Format("02/01")
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", date, tableCell(tx.ItemName), tableCell(tx.Merchant), tools.FormatRupiah(tx.Amount)))
+	}
+	return sb.String()
+}
+
+// tableCell keeps a value from breaking the markdown table.
+func tableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "/")
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -3,14 +3,21 @@ package telegram
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"iter"
+	"log"
 	"mime"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
+	"finagent/internal/agent/hitl"
+	"finagent/internal/i18n"
+	"finagent/internal/imageprep"
+
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
@@ -18,14 +25,25 @@ import (
 )
 
 type Stage struct {
-	Type         string // "text", "tool_call", "tool_result"
-	Content      string
-	ShouldDelete bool
+	Type    string // "text", "partial", "tool_call", "tool_result"
+	Tool    string // tool name for tool stages
+	Content string
+}
+
+// StageFunc receives stages while the agent is still running. "partial"
+// stages carry the text streamed so far and are not kept in ProcessResult.
+type StageFunc func(Stage)
+
+// Attachment is a downloaded file sent to the model as an inline part.
+type Attachment struct {
+	Path     string
+	MimeType string
 }
 
 type ProcessResult struct {
-	Stages []Stage
-	Error  error
+	Stages  []Stage
+	Pending []hitl.PendingAction
+	Error   error
 }
 
 type BotRunner struct {
@@ -33,6 +51,11 @@ type BotRunner struct {
 	sessionService session.Service
 	sessions       map[int64]string
 	sessionMu      sync.RWMutex
+	sessionsPath   string // chat → session map on disk; "" keeps it in memory
+	policy         SessionPolicy
+	summarizer     Summarizer
+	limiter        *limiter // nil: no limits
+	imageOptions   imageprep.Options
 	logger         *ToolLogger
 }
 
@@ -41,11 +64,67 @@ func NewBotRunner(r *runner.Runner, sessionService session.Service, logger *Tool
 		runner:         r,
 		sessionService: sessionService,
 		sessions:       make(map[int64]string),
+		imageOptions:   imageprep.DefaultOptions(),
 		logger:         logger,
 	}
 }
 
-func (br *BotRunner) ProcessMessage(ctx context.Context, chatID int64, text, photoPath string) (*ProcessResult, error) {
+// SetImageOptions changes how photos are prepared before they are sent.
+func (br *BotRunner) SetImageOptions(opts imageprep.Options) {
+	br.imageOptions = opts
+}
+
+// SetRateLimit limits how often each user may run the agent and how many
+// runs happen at once. Rejected runs return a *RateLimitError.
+func (br *BotRunner) SetRateLimit(limit RateLimit) {
+	br.limiter = newLimiter(limit)
+}
+
+// ProcessMessage runs one agent turn with the text and any number of
+// attachments (one per receipt, e.g. from an album, or a voice note).
+// onStage, if not nil, is called as the run progresses.
+func (br *BotRunner) ProcessMessage(ctx context.Context, chatID int64, text string, attachments []Attachment, onStage StageFunc) (*ProcessResult, error) {
+	userMsg, err := br.createContent(text, attachments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create content: %w", err)
+	}
+	return br.run(ctx, chatID, userMsg, onStage)
+}
+
+// Resume runs the agent without a new user message, e.g. after a
+// confirmation button recorded the user's decision in the session.
+func (br *BotRunner) Resume(ctx context.Context, chatID int64, onStage StageFunc) (*ProcessResult, error) {
+	return br.run(ctx, chatID, nil, onStage)
+}
+
+// run executes one agent turn. ProcessResult.Pending holds only the actions
+// created during this turn; older ones already have their buttons.
+func (br *BotRunner) run(ctx context.Context, chatID int64, userMsg *genai.Content, onStage StageFunc) (*ProcessResult, error) {
+	if br.limiter != nil {
+		// Each group member has their own budget
+		key := chatID
+		if sender, ok := SenderFromContext(ctx); ok {
+			key = sender
+		}
+		// Resuming after a confirmation was paid for by the message that
+		// proposed the action, so it only needs a slot
+		release, err := br.limiter.acquire(ctx, key, userMsg != nil)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	if err := br.maintainSession(ctx, chatID); err != nil {
+		// An oversized session still works, so carry on with it
+		br.logger.LogError(ErrorLog{
+			ChatID:    chatID,
+			UserID:    fmt.Sprintf("tg_%d", chatID),
+			Component: "session_maintenance",
+			Error:     err.Error(),
+		})
+	}
+
 	sessionID, err := br.getOrCreateSession(ctx, chatID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get session: %w", err)
@@ -53,9 +132,9 @@ func (br *BotRunner) ProcessMessage(ctx context.Context, chatID int64, text, pho
 
 	userID := fmt.Sprintf("tg_%d", chatID)
 
-	userMsg, err := br.createContent(text, photoPath)
+	before, err := br.PendingActions(ctx, chatID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create content: %w", err)
+		return nil, fmt.Errorf("failed to load pending actions: %w", err)
 	}
 
 	events := br.runner.Run(
@@ -64,12 +143,134 @@ func (br *BotRunner) ProcessMessage(ctx context.Context, chatID int64, text, pho
 		sessionID,
 		userMsg,
 		agent.RunConfig{
-			StreamingMode:             agent.StreamingModeNone,
+			StreamingMode:             agent.StreamingModeSSE,
 			SaveInputBlobsAsArtifacts: true,
 		},
 	)
 
-	return br.parseEvents(ctx, chatID, userID, events), nil
+	if onStage == nil {
+		onStage = func(Stage) {}
+	}
+	result := br.parseEvents(ctx, chatID, userID, events, onStage)
+
+	after, err := br.PendingActions(ctx, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pending actions: %w", err)
+	}
+
+	known := make(map[string]bool, len(before))
+	for _, a := range before {
+		known[a.ID] = true
+	}
+	for _, a := range after {
+		if !known[a.ID] {
+			result.Pending = append(result.Pending, a)
+		}
+	}
+	return result, nil
+}
+
+// PendingActions returns the mutations in this chat waiting for a decision.
+func (br *BotRunner) PendingActions(ctx context.Context, chatID int64) ([]hitl.PendingAction, error) {
+	ref, err := br.sessionRef(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	return hitl.List(ctx, br.sessionService, ref)
+}
+
+// ResolveAction applies the decision of a pressed confirmation button:
+// commit, cancel or hand the action back to the agent for editing.
+func (br *BotRunner) ResolveAction(ctx context.Context, chatID int64, actionID string, decision hitl.Decision) (*hitl.Resolution, error) {
+	ref, err := br.sessionRef(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := hitl.Decide(ctx, br.sessionService, ref, actionID, decision)
+	if err != nil {
+		return nil, err
+	}
+
+	errMsg := ""
+	if res.Err != nil {
+		errMsg = res.Err.Error()
+	}
+	br.logger.LogToolResult(chatID, ref.UserID, res.Action.Tool, map[string]interface{}{
+		"action_id": res.Action.ID,
+		"decision":  string(decision),
+		"message":   res.Message,
+	}, errMsg, 0)
+
+	return res, nil
+}
+
+// Language returns the chat's language. On first contact it is detected from
+// the Telegram language code and stored so the agent replies in it too.
+func (br *BotRunner) Language(ctx context.Context, chatID int64, languageCode string) i18n.Lang {
+	sess, err := br.getSession(ctx, chatID)
+	if err != nil {
+		return i18n.Detect(languageCode)
+	}
+	if lang, ok := i18n.FromState(sess.State().Get); ok {
+		return lang
+	}
+
+	lang := i18n.Detect(languageCode)
+	if err := br.storeLanguage(ctx, sess, lang); err != nil {
+		log.Printf("⚠️ Failed to store language for chat %d: %v", chatID, err)
+	}
+	return lang
+}
+
+// SetLanguage changes the chat's language for bot texts and agent replies.
+func (br *BotRunner) SetLanguage(ctx context.Context, chatID int64, lang i18n.Lang) error {
+	sess, err := br.getSession(ctx, chatID)
+	if err != nil {
+		return err
+	}
+	return br.storeLanguage(ctx, sess, lang)
+}
+
+// storeLanguage appends a state-only event, which the model never sees.
+func (br *BotRunner) storeLanguage(ctx context.Context, sess session.Session, lang i18n.Lang) error {
+	ev := session.NewEvent("lang_" + string(lang))
+	ev.Author = "user"
+	ev.Actions.StateDelta[i18n.StateKey] = string(lang)
+
+	if err := br.sessionService.AppendEvent(ctx, sess, ev); err != nil {
+		return fmt.Errorf("failed to store language: %w", err)
+	}
+	return nil
+}
+
+func (br *BotRunner) getSession(ctx context.Context, chatID int64) (session.Session, error) {
+	ref, err := br.sessionRef(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := br.sessionService.Get(ctx, &session.GetRequest{
+		AppName:   ref.AppName,
+		UserID:    ref.UserID,
+		SessionID: ref.SessionID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return resp.Session, nil
+}
+
+func (br *BotRunner) sessionRef(ctx context.Context, chatID int64) (hitl.SessionRef, error) {
+	sessionID, err := br.getOrCreateSession(ctx, chatID)
+	if err != nil {
+		return hitl.SessionRef{}, fmt.Errorf("failed to get session: %w", err)
+	}
+	return hitl.SessionRef{
+		AppName:   "financial_tracker",
+		UserID:    fmt.Sprintf("tg_%d", chatID),
+		SessionID: sessionID,
+	}, nil
 }
 
 func (br *BotRunner) getOrCreateSession(ctx context.Context, chatID int64) (string, error) {
@@ -93,86 +294,167 @@ func (br *BotRunner) getOrCreateSession(ctx context.Context, chatID int64) (stri
 	}
 
 	br.sessions[chatID] = sess.Session.ID()
+	if err := br.saveSessionsLocked(); err != nil {
+		// The session still works until the next restart
+		log.Printf("⚠️ %v", err)
+	}
 	return sess.Session.ID(), nil
 }
 
-func (br *BotRunner) parseEvents(ctx context.Context, chatID int64, userID string, events iter.Seq2[*session.Event, error]) *ProcessResult {
+// LoadSessions restores the chat → session map from path and keeps it
+// there from now on. Entries whose session no longer exists are dropped.
+// Use it with a persistent session.Service so chats resume after restart.
+func (br *BotRunner) LoadSessions(ctx context.Context, path string) error {
+	br.sessionMu.Lock()
+	defer br.sessionMu.Unlock()
+
+	br.sessionsPath = path
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read sessions: %w", err)
+	}
+
+	var saved map[int64]string
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	for chatID, sessionID := range saved {
+		_, err := br.sessionService.Get(ctx, &session.GetRequest{
+			AppName:         "financial_tracker",
+			UserID:          fmt.Sprintf("tg_%d", chatID),
+			SessionID:       sessionID,
+			NumRecentEvents: 1,
+		})
+		if err != nil {
+			log.Printf("⚠️ Session %s of chat %d is gone, starting fresh", sessionID, chatID)
+			continue
+		}
+		br.sessions[chatID] = sessionID
+	}
+
+	log.Printf("✓ Restored %d chat sessions", len(br.sessions))
+	return nil
+}
+
+func (br *BotRunner) saveSessionsLocked() error {
+	if br.sessionsPath == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(br.sessionsPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(br.sessions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sessions: %w", err)
+	}
+	if err := os.WriteFile(br.sessionsPath, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to save sessions: %w", err)
+	}
+	return nil
+}
+
+func (br *BotRunner) parseEvents(ctx context.Context, chatID int64, userID string, events iter.Seq2[*session.Event, error], onStage StageFunc) *ProcessResult {
 	result := &ProcessResult{
 		Stages: []Stage{},
 	}
 
+	emit := func(stage Stage) {
+		result.Stages = append(result.Stages, stage)
+		onStage(stage)
+	}
+
+	// Streamed text chunks; the complete text follows as a non-partial event
+	var streamed strings.Builder
+
 	for event, err := range events {
 		if err != nil {
 			result.Error = err
-			result.Stages = append(result.Stages, Stage{
-				Type:         "text",
-				Content:      fmt.Sprintf("❌ Error: %v", err),
-				ShouldDelete: false,
+			emit(Stage{
+				Type:    "text",
+				Content: fmt.Sprintf("❌ Error: %v", err),
 			})
 			continue
 		}
 
-		if event.Content != nil {
+		if event.Content == nil {
+			continue
+		}
+
+		if event.Partial {
 			for _, part := range event.Content.Parts {
-				// AI text response
-				if part.Text != "" {
-					result.Stages = append(result.Stages, Stage{
-						Type:         "text",
-						Content:      part.Text,
-						ShouldDelete: false,
-					})
+				if part.Text != "" && !part.Thought {
+					streamed.WriteString(part.Text)
+					onStage(Stage{Type: "partial", Content: streamed.String()})
 				}
+			}
+			continue
+		}
+		streamed.Reset()
+
+		for _, part := range event.Content.Parts {
+			// AI text response
+			if part.Text != "" && !part.Thought {
+				emit(Stage{
+					Type:    "text",
+					Content: part.Text,
+				})
+			}
+
+			// Tool call
+			if part.FunctionCall != nil {
+				startTime := time.Now()
+				toolName := part.FunctionCall.Name
 
-				// Tool call
-				if part.FunctionCall != nil {
-					startTime := time.Now()
-					toolName := part.FunctionCall.Name
+				br.logger.LogToolCall(chatID, userID, toolName, part.FunctionCall.Args)
 
-					br.logger.LogToolCall(chatID, userID, toolName, part.FunctionCall.Args)
+				emit(Stage{
+					Type:    "tool_call",
+					Tool:    toolName,
+					Content: fmt.Sprintf("🔧 %s…", toolName),
+				})
 
-					content := fmt.Sprintf("🔧 Tool: %s", toolName)
-					result.Stages = append(result.Stages, Stage{
-						Type:         "tool_call",
-						Content:      content,
-						ShouldDelete: true,
-					})
+				// Store start time for duration calculation
+				ctx = context.WithValue(ctx, toolName+"_start", startTime)
+			}
+
+			// Tool result
+			if part.FunctionResponse != nil {
+				toolName := part.FunctionResponse.Name
+				resp := part.FunctionResponse.Response
 
-					// Store start time for duration calculation
-					ctx = context.WithValue(ctx, toolName+"_start", startTime)
+				// Calculate duration
+				var duration time.Duration
+				if startTime, ok := ctx.Value(toolName + "_start").(time.Time); ok {
+					duration = time.Since(startTime)
 				}
 
-				// Tool result
-				if part.FunctionResponse != nil {
-					toolName := part.FunctionResponse.Name
-					resp := part.FunctionResponse.Response
-
-					// Calculate duration
-					var duration time.Duration
-					if startTime, ok := ctx.Value(toolName + "_start").(time.Time); ok {
-						duration = time.Since(startTime)
-					}
-
-					// Log result
-					errMsg := ""
-					if errVal, ok := resp["error"].(string); ok {
-						errMsg = errVal
-					}
-					br.logger.LogToolResult(chatID, userID, toolName, resp, errMsg, duration)
-
-					// Format result message
-					content := fmt.Sprintf("✓ Result: %s", toolName)
-					if msg, ok := resp["message"].(string); ok {
-						content += "\n" + msg
-					} else if status, ok := resp["status"].(string); ok && status == "success" {
-						content += "\n✅ Success"
-					}
-
-					result.Stages = append(result.Stages, Stage{
-						Type:         "tool_result",
-						Content:      content,
-						ShouldDelete: true,
-					})
+				// Log result
+				errMsg := ""
+				if errVal, ok := resp["error"].(string); ok {
+					errMsg = errVal
 				}
+				br.logger.LogToolResult(chatID, userID, toolName, resp, errMsg, duration)
+
+				// Format result line
+				content := fmt.Sprintf("✓ %s", toolName)
+				if status, _ := resp["status"].(string); status == "pending_confirmation" {
+					content = fmt.Sprintf("⏳ %s", toolName)
+				} else if errMsg != "" || status == "error" {
+					content = fmt.Sprintf("⚠️ %s", toolName)
+				}
+
+				emit(Stage{
+					Type:    "tool_result",
+					Tool:    toolName,
+					Content: content,
+				})
 			}
 		}
 	}
@@ -180,25 +462,50 @@ func (br *BotRunner) parseEvents(ctx context.Context, chatID int64, userID strin
 	return result
 }
 
-func (br *BotRunner) createContent(text, imagePath string) (*genai.Content, error) {
+func (br *BotRunner) createContent(text string, attachments []Attachment) (*genai.Content, error) {
 	parts := []*genai.Part{}
 
 	if text != "" {
 		parts = append(parts, genai.NewPartFromText(text))
 	}
 
-	if imagePath != "" {
-		imageData, err := os.ReadFile(imagePath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read image: %w", err)
+	images := 0
+	for _, att := range attachments {
+		if !strings.HasPrefix(att.MimeType, "audio/") {
+			images++
 		}
+	}
+	if images > 1 {
+		parts = append(parts, genai.NewPartFromText(fmt.Sprintf("[album] %d receipt images sent together", images)))
+	}
 
-		mimeType := mime.TypeByExtension(filepath.Ext(imagePath))
+	for _, att := range attachments {
+		mimeType := att.MimeType
+		if mimeType == "" {
+			mimeType = mime.TypeByExtension(filepath.Ext(att.Path))
+		}
 		if mimeType == "" {
 			mimeType = "application/octet-stream"
 		}
 
-		parts = append(parts, genai.NewPartFromBytes(imageData, mimeType))
+		if strings.HasPrefix(mimeType, "audio/") {
+			data, err := os.ReadFile(att.Path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read attachment: %w", err)
+			}
+			parts = append(parts, genai.NewPartFromText("[voice note]"), genai.NewPartFromBytes(data, mimeType))
+			continue
+		}
+
+		// Images are oriented, downscaled and re-encoded; PDFs pass through
+		img, err := imageprep.PrepareFile(att.Path, br.imageOptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare attachment: %w", err)
+		}
+		if img.Width > 0 {
+			log.Printf("🖼️ Prepared %s: %dx%d %s, %d KB (was %d KB)", filepath.Base(att.Path), img.Width, img.Height, img.MimeType, len(img.Data)/1024, img.Original/1024)
+		}
+		parts = append(parts, genai.NewPartFromBytes(img.Data, img.MimeType))
 	}
 
 	return &genai.Content{
@@ -5,14 +5,57 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"finagent/internal/imageprep"
 )
 
 type BotConfig struct {
+	// EnableMarkdown renders the agent's markdown as Telegram HTML (tables
+	// become monospace); otherwise replies are sent as plain text.
 	EnableMarkdown bool
 	LogDir         string
 	PhotoTempDir   string
-	DeleteDelay    time.Duration
-	MaxPhotoSize   int64
+	// ProgressInterval throttles edits of the progress message while text
+	// streams in (Telegram rate-limits edits).
+	ProgressInterval time.Duration
+	MaxPhotoSize     int64
+	// Image controls how photos are oriented, downscaled and re-encoded
+	// before they reach the model.
+	Image imageprep.Options
+	// MaxVoiceDuration limits voice notes sent to the model.
+	MaxVoiceDuration time.Duration
+	// MaxQueuedMessages is how many messages a chat may have waiting while
+	// the previous one is processed; more get a "busy" reply.
+	MaxQueuedMessages int
+	// MediaGroupWait is how long to wait for more photos of an album.
+	MediaGroupWait time.Duration
+	// ShutdownTimeout is how long shutdown waits for queued and running
+	// messages before cancelling them.
+	ShutdownTimeout time.Duration
+	// EnableGroups lets the bot work in group chats, where it answers
+	// commands, mentions and replies to its messages. A group has one
+	// shared session and spreadsheet; members need their own access.
+	EnableGroups bool
+	// RateLimit protects the Gemini and Sheets quotas from bursts.
+	RateLimit RateLimit
+	// AccessPath stores the allowlist and pending invites.
+	AccessPath string
+	// SchedulePath stores the digest schedules of every chat.
+	SchedulePath string
+	// SessionDir holds the session journals and the chat → session map,
+	// so conversations and pending confirmations survive restarts.
+	SessionDir string
+	// Session rotates idle conversations and compacts long ones so each
+	// run stays small.
+	Session SessionPolicy
+
+	// Webhook mode is used when WebhookListen (e.g. ":8080") is set.
+	// WebhookURL is the public base URL registered with Telegram; leave it
+	// empty to only accept local POSTs. It requires WebhookSecret.
+	WebhookListen string
+	WebhookURL    string
+	WebhookPath   string
+	WebhookSecret string
 }
 
 func DefaultConfig() BotConfig {
@@ -20,11 +63,33 @@ func DefaultConfig() BotConfig {
 	tempDir := detectTempDir()
 
 	return BotConfig{
-		EnableMarkdown: false,
-		LogDir:         "./logs",
-		PhotoTempDir:   tempDir,
-		DeleteDelay:    500 * time.Millisecond,
-		MaxPhotoSize:   10 * 1024 * 1024, // 10MB
+		EnableMarkdown:   true,
+		LogDir:           "./logs",
+		PhotoTempDir:     tempDir,
+		ProgressInterval: time.Second,
+		MaxPhotoSize:     10 * 1024 * 1024, // 10MB
+		Image:            imageprep.DefaultOptions(),
+
+		MaxVoiceDuration: 2 * time.Minute,
+
+		MaxQueuedMessages: 3,
+		MediaGroupWait:    1500 * time.Millisecond,
+		ShutdownTimeout:   30 * time.Second,
+		RateLimit: RateLimit{
+			PerMinute:     6,
+			Burst:         5,
+			MaxConcurrent: 4,
+			QueueWait:     20 * time.Second,
+		},
+		AccessPath:   "./data/access.json",
+		SchedulePath: "./data/schedules.json",
+		SessionDir:   "./data/sessions",
+		Session: SessionPolicy{
+			IdleTimeout: 12 * time.Hour,
+			MaxEvents:   60,
+			KeepEvents:  12,
+		},
+		WebhookPath: "/telegram/webhook",
 	}
 }
 
@@ -0,0 +1,189 @@
+// go-agent-tracker/internal/telegram/digest.go
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"finagent/internal/agent/tools"
+	"finagent/internal/i18n"
+	"finagent/internal/scheduler"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Digests are spending summaries the bot pushes on a schedule: daily
+// (today so far), weekly and monthly (the previous full week or month).
+// A chat has at most one of each; the job ID is the frequency.
+
+const digestJob = "digest"
+
+var defaultDigests = map[string]scheduler.Schedule{
+	scheduler.Daily:   {Every: scheduler.Daily, Hour: 21},
+	scheduler.Weekly:  {Every: scheduler.Weekly, Weekday: time.Monday, Hour: 8},
+	scheduler.Monthly: {Every: scheduler.Monthly, Day: 1, Hour: 8},
+}
+
+// SetScheduler enables digests (and other scheduled jobs). Jobs run while
+// the bot is started.
+func (tb *TelegramBot) SetScheduler(s *scheduler.Scheduler) {
+	tb.scheduler = s
+}
+
+// runScheduler fires scheduled jobs until shutdown begins. Each job runs
+// in its chat's queue, after whatever the chat is doing.
+func (tb *TelegramBot) runScheduler() {
+	if tb.scheduler == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(tb.ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-tb.stopping:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	tb.scheduler.Run(ctx, func(job scheduler.Job) {
+		var run func()
+		switch job.Type {
+		case digestJob:
+			run = func() { tb.sendDigest(job) }
+		case billJob:
+			run = func() { tb.sendBillReminder(job) }
+		default:
+			log.Printf("⚠️ Unknown scheduled job type %q", job.Type)
+			return
+		}
+
+		if !tb.queue.Submit(job.ChatID, run) {
+			log.Printf("⚠️ Chat %d: skipped %s %s, the chat is busy", job.ChatID, job.Type, job.ID)
+		}
+	})
+}
+
+// handleDigest: /digest shows the chat's digests; /digest daily [HH:MM],
+// /digest weekly [weekday] [HH:MM] and /digest monthly [day] [HH:MM] set
+// one; /digest off [daily|weekly|monthly] stops them.
+func (tb *TelegramBot) handleDigest(msg *tgbotapi.Message, lang i18n.Lang) {
+	chatID := msg.Chat.ID
+
+	if _, ok := tb.role(msg.From); !ok || tb.scheduler == nil {
+		tb.sendMessage(chatID, i18n.T(lang, "access.denied"), false)
+		return
+	}
+
+	args := strings.Fields(strings.ToLower(msg.CommandArguments()))
+	if len(args) == 0 {
+		tb.sendMessage(chatID, tb.digestList(chatID, lang), false)
+		return
+	}
+
+	if args[0] == "off" {
+		every := []string{scheduler.Daily, scheduler.Weekly, scheduler.Monthly}
+		if len(args) > 1 {
+			every = args[1:2]
+		}
+
+		removed := 0
+		for _, id := range every {
+			ok, err := tb.scheduler.Remove(chatID, digestJob, id)
+			if err != nil {
+				tb.ledgerError(chatID, lang, "digest_remove", err)
+				return
+			}
+			if ok {
+				removed++
+			}
+		}
+		tb.sendMessage(chatID, i18n.T(lang, "digest.off", removed), false)
+		return
+	}
+
+	def, ok := defaultDigests[args[0]]
+	if !ok {
+		tb.sendMessage(chatID, i18n.T(lang, "digest.usage"), false)
+		return
+	}
+	sched, err := scheduler.ParseSchedule(strings.Join(args, " "), def)
+	if err != nil {
+		tb.sendMessage(chatID, i18n.T(lang, "digest.invalid", err)+"\n\n"+i18n.T(lang, "digest.usage"), false)
+		return
+	}
+
+	job, err := tb.scheduler.Add(scheduler.Job{
+		ID:       sched.Every,
+		ChatID:   chatID,
+		Type:     digestJob,
+		Schedule: sched,
+	})
+	if err != nil {
+		tb.ledgerError(chatID, lang, "digest_add", err)
+		return
+	}
+
+	log.Printf("🗓️ Chat %d: %s digest at %s", chatID, sched.Every, sched)
+	tb.sendMessage(chatID, i18n.T(lang, "digest.set", i18n.T(lang, "digest."+sched.Every), sched, job.Next.Format("Mon 2 Jan 15:04")), false)
+}
+
+func (tb *TelegramBot) digestList(chatID int64, lang i18n.Lang) string {
+	jobs := tb.scheduler.Jobs(chatID, digestJob)
+	if len(jobs) == 0 {
+		return i18n.T(lang, "digest.none") + "\n\n" + i18n.T(lang, "digest.usage")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, "digest.list"))
+	for _, job := range jobs {
+		sb.WriteString(fmt.Sprintf("\n• %s — %s (%s)", i18n.T(lang, "digest."+job.ID), job.Schedule, job.Next.Format("Mon 2 Jan 15:04")))
+	}
+	return sb.String()
+}
+
+// sendDigest pushes the summary of a digest job. Weekly and monthly ones
+// compare with the period before.
+func (tb *TelegramBot) sendDigest(job scheduler.Job) {
+	chatID := job.ChatID
+	lang := tb.runner.Language(tb.ctx, chatID, "")
+
+	txs, err := tools.Transactions(tb.ledgerContext(chatID))
+	if err != nil {
+		tb.ledgerError(chatID, lang, "digest_send", err)
+		return
+	}
+
+	from, to, prevFrom := digestRange(job.Schedule.Every, tb.scheduler.Now())
+	summary := tools.Summarize(txs, from, to)
+	previous := tools.Summarize(txs, prevFrom, from)
+
+	text := summaryText(lang, "🗓️ "+i18n.T(lang, "digest."+job.Schedule.Every), summary)
+	if previous.Total > 0 && job.Schedule.Every != scheduler.Daily {
+		change := (summary.Total - previous.Total) / previous.Total * 100
+		text += "\n" + i18n.T(lang, "digest.change", tools.FormatRupiah(previous.Total), fmt.Sprintf("%+.0f%%", change))
+	}
+
+	log.Printf("🗓️ Chat %d: sent %s digest", chatID, job.Schedule.Every)
+	tb.sendMessage(chatID, text, tb.config.EnableMarkdown)
+}
+
+// digestRange returns the period [from, to) a digest covers and the start
+// of the period before it: today for daily, the previous Monday–Sunday
+// for weekly and the previous calendar month for monthly.
+func digestRange(every string, now time.Time) (from, to, prevFrom time.Time) {
+	switch every {
+	case scheduler.Weekly:
+		monday, _ := periodRange("week", now)
+		return monday.AddDate(0, 0, -7), monday, monday.AddDate(0, 0, -14)
+	case scheduler.Monthly:
+		first, _ := periodRange("month", now)
+		return first.AddDate(0, -1, 0), first, first.AddDate(0, -2, 0)
+	}
+	from, to = periodRange("today", now)
+	return from, to, from.AddDate(0, 0, -1)
+}
@@ -0,0 +1,110 @@
+// go-agent-tracker/internal/telegram/group.go
+package telegram
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"finagent/internal/agent/tools"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Groups share one session and one spreadsheet per chat ("tg_<chatID>"),
+// like a private chat. Members still need their own access role.
+
+// isGroup reports whether chat is a group or supergroup.
+func isGroup(chat *tgbotapi.Chat) bool {
+	return chat != nil && (chat.IsGroup() || chat.IsSuperGroup())
+}
+
+// addressed reports whether a group message is meant for the bot: a
+// command (not one for another bot), a mention, or a reply to the bot.
+func (tb *TelegramBot) addressed(msg *tgbotapi.Message) bool {
+	if msg.IsCommand() {
+		_, target, found := strings.Cut(msg.CommandWithAt(), "@")
+		return !found || strings.EqualFold(target, tb.bot.Self.UserName)
+	}
+
+	if reply := msg.ReplyToMessage; reply != nil && reply.From != nil && reply.From.ID == tb.bot.Self.ID {
+		return true
+	}
+
+	entities, text := msg.Entities, msg.Text
+	if msg.Caption != "" {
+		entities, text = msg.CaptionEntities, msg.Caption
+	}
+	for _, entity := range entities {
+		switch entity.Type {
+		case "mention":
+			if strings.EqualFold(entityText(text, entity), "@"+tb.bot.Self.UserName) {
+				return true
+			}
+		case "text_mention":
+			if entity.User != nil && entity.User.ID == tb.bot.Self.ID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// anyAddressed reports whether any message of an album is addressed to
+// the bot (usually the one with the caption).
+func (tb *TelegramBot) anyAddressed(msgs []*tgbotapi.Message) bool {
+	for _, msg := range msgs {
+		if tb.addressed(msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripMention removes the bot's @username from a group message.
+func (tb *TelegramBot) stripMention(text string) string {
+	mention := regexp.MustCompile(`(?i)[ \t]*@` + regexp.QuoteMeta(tb.bot.Self.UserName) + `\b`)
+	return strings.TrimSpace(mention.ReplaceAllString(text, ""))
+}
+
+// entityText returns the text an entity covers; offsets count UTF-16 units.
+func entityText(text string, entity tgbotapi.MessageEntity) string {
+	var sb strings.Builder
+	pos := 0
+	for _, r := range text {
+		if pos >= entity.Offset+entity.Length {
+			break
+		}
+		if pos >= entity.Offset {
+			sb.WriteRune(r)
+		}
+		pos++
+		if r >= 0x10000 {
+			pos++ // surrogate pair
+		}
+	}
+	return sb.String()
+}
+
+// withGroupAuthor names the sender for added_by in group chats, where
+// several members share a ledger. A private chat has a single author, so
+// its rows leave added_by empty.
+func withGroupAuthor(ctx context.Context, chat *tgbotapi.Chat, from *tgbotapi.User) context.Context {
+	if !isGroup(chat) {
+		return ctx
+	}
+	return tools.WithAuthor(ctx, senderName(from))
+}
+
+// senderName is how a member is named in the ledger (added_by) and to the
+// agent: the full name, else the username.
+func senderName(user *tgbotapi.User) string {
+	if user == nil {
+		return ""
+	}
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if name == "" && user.UserName != "" {
+		name = "@" + user.UserName
+	}
+	return name
+}
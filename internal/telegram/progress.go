@@ -0,0 +1,140 @@
+// go-agent-tracker/internal/telegram/progress.go
+package telegram
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// progressMessage is the single status message of an agent turn. It is
+// edited as tool calls progress and text streams in, then replaced by the
+// final answer.
+type progressMessage struct {
+	tb     *TelegramBot
+	chatID int64
+	msgID  int
+
+	tools []string // one line per tool call: "🔧 list_sheets…" → "✓ list_sheets"
+	texts []string // completed agent texts
+	draft string   // text still streaming
+
+	shown    string
+	lastEdit time.Time
+}
+
+func (tb *TelegramBot) newProgress(chatID int64) *progressMessage {
+	tb.bot.Request(tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping))
+	return &progressMessage{tb: tb, chatID: chatID}
+}
+
+// Update is the StageFunc of the run. Tool changes are shown right away,
+// streamed text at most every ProgressInterval.
+func (p *progressMessage) Update(stage Stage) {
+	switch stage.Type {
+	case "tool_call":
+		p.tools = append(p.tools, stage.Content)
+		p.render(true)
+
+	case "tool_result":
+		running := "🔧 " + stage.Tool + "…"
+		for i := len(p.tools) - 1; i >= 0; i-- {
+			if p.tools[i] == running {
+				p.tools[i] = stage.Content
+				break
+			}
+		}
+		p.render(true)
+
+	case "partial":
+		p.draft = stage.Content
+		p.render(false)
+
+	case "text":
+		p.texts = append(p.texts, stage.Content)
+		p.draft = ""
+		p.render(false)
+	}
+}
+
+// Finish replaces the status with the final answer (and its buttons). An
+// empty text keeps the tool lines as the last state.
+func (p *progressMessage) Finish(text string, markup *tgbotapi.InlineKeyboardMarkup) int {
+	if text == "" {
+		text = strings.Join(p.tools, "\n")
+	}
+	if text == "" {
+		return p.msgID
+	}
+	if p.tb.config.EnableMarkdown {
+		p.shown = "" // same text, but now rendered
+	}
+	p.show(text, markup, p.tb.config.EnableMarkdown)
+	return p.msgID
+}
+
+func (p *progressMessage) render(force bool) {
+	if !force && time.Since(p.lastEdit) < p.tb.config.ProgressInterval {
+		return
+	}
+
+	var sections []string
+	if len(p.tools) > 0 {
+		sections = append(sections, strings.Join(p.tools, "\n"))
+	}
+	texts := p.texts
+	if p.draft != "" {
+		texts = append(texts[:len(texts):len(texts)], p.draft+" ▍")
+	}
+	if len(texts) > 0 {
+		sections = append(sections, strings.Join(texts, "\n\n"))
+	}
+
+	// Partial markdown rarely parses, so progress is always plain text.
+	// Only the tail fits while a long answer streams in.
+	text := strings.Join(sections, "\n\n")
+	if runes := []rune(text); len(runes) > maxMessageLength/2 {
+		text = "…" + string(runes[len(runes)-maxMessageLength/2:])
+	}
+	p.show(text, nil, false)
+}
+
+func (p *progressMessage) show(text string, markup *tgbotapi.InlineKeyboardMarkup, enableMarkdown bool) {
+	if strings.TrimSpace(text) == "" || (text == p.shown && markup == nil) {
+		return
+	}
+	// Markdown that renders to nothing (e.g. a bare "```") is sent as typed
+	if enableMarkdown && len(p.tb.chunks(text, true)) == 0 {
+		enableMarkdown = false
+	}
+
+	if p.msgID == 0 {
+		msg := tgbotapi.NewMessage(p.chatID, text)
+		if markup != nil {
+			msg.ReplyMarkup = *markup
+		}
+		p.msgID = p.tb.send(msg, enableMarkdown)
+	} else {
+		// A long answer continues in new messages, buttons on the last
+		chunks := p.tb.chunks(text, enableMarkdown)
+		edit := tgbotapi.NewEditMessageText(p.chatID, p.msgID, chunks[0])
+		if len(chunks) == 1 {
+			edit.ReplyMarkup = markup
+		}
+		if err := p.tb.edit(edit, enableMarkdown); err != nil {
+			log.Printf("❌ Failed to update progress message: %v", err)
+		}
+		for i, chunk := range chunks[1:] {
+			msg := tgbotapi.NewMessage(p.chatID, chunk)
+			if markup != nil && i == len(chunks)-2 {
+				msg.ReplyMarkup = *markup
+			}
+			p.msgID = p.tb.sendChunk(msg, enableMarkdown)
+		}
+	}
+
+	p.shown = text
+	p.lastEdit = time.Now()
+}
@@ -0,0 +1,95 @@
+package telegram
+
+import (
+	"context"
+	"sync"
+)
+
+// chatQueue runs the jobs of each chat one at a time, in order, while
+// different chats run in parallel. A chat's worker goroutine exits once its
+// queue is empty.
+type chatQueue struct {
+	mu     sync.Mutex
+	queues map[int64]chan func()
+	size   int
+	closed bool
+	jobs   sync.WaitGroup // submitted jobs not finished yet
+}
+
+func newChatQueue(size int) *chatQueue {
+	if size < 1 {
+		size = 1
+	}
+	return &chatQueue{
+		queues: make(map[int64]chan func()),
+		size:   size,
+	}
+}
+
+// Submit queues job for chatID. It returns false when the chat already has
+// size jobs waiting or the queue is closed.
+func (q *chatQueue) Submit(chatID int64, job func()) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return false
+	}
+
+	jobs, ok := q.queues[chatID]
+	if !ok {
+		jobs = make(chan func(), q.size)
+		q.queues[chatID] = jobs
+		go q.work(chatID, jobs)
+	}
+
+	q.jobs.Add(1)
+	select {
+	case jobs <- job:
+		return true
+	default:
+		q.jobs.Done()
+		return false
+	}
+}
+
+// Close rejects new jobs; the queued ones still run.
+func (q *chatQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+}
+
+// Wait blocks until every submitted job has finished or ctx is done.
+func (q *chatQueue) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		q.jobs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *chatQueue) work(chatID int64, jobs chan func()) {
+	for {
+		select {
+		case job := <-jobs:
+			job()
+			q.jobs.Done()
+		default:
+			q.mu.Lock()
+			if len(jobs) == 0 {
+				delete(q.queues, chatID)
+				q.mu.Unlock()
+				return
+			}
+			q.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockChat submits a job that holds chatID's worker until the returned
+// function is called.
+func blockChat(t *testing.T, q *chatQueue, chatID int64) (unblock func()) {
+	t.Helper()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	if !q.Submit(chatID, func() {
+		close(started)
+		<-release
+	}) {
+		t.Fatal("blocking job was rejected")
+	}
+	<-started
+	var once sync.Once
+	return func() { once.Do(func() { close(release) }) }
+}
+
+func TestChatQueueOrder(t *testing.T) {
+	q := newChatQueue(10)
+	unblock := blockChat(t, q, 1)
+
+	var mu sync.Mutex
+	var got []int
+	for i := range 5 {
+		if !q.Submit(1, func() {
+			mu.Lock()
+			got = append(got, i)
+			mu.Unlock()
+		}) {
+			t.Fatalf("job %d was rejected", i)
+		}
+	}
+	unblock()
+
+	if err := q.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{0, 1, 2, 3, 4}; !slices.Equal(got, want) {
+		t.Errorf("jobs ran in order %v, want %v", got, want)
+	}
+}
+
+func TestChatQueueOverflow(t *testing.T) {
+	const size = 3
+	q := newChatQueue(size)
+	unblock := blockChat(t, q, 1)
+	defer unblock()
+
+	// The running job does not count; size more may wait behind it
+	for i := range size {
+		if !q.Submit(1, func() {}) {
+			t.Fatalf("job %d of %d was rejected", i+1, size)
+		}
+	}
+	if q.Submit(1, func() {}) {
+		t.Error("job over the limit was accepted")
+	}
+
+	// Other chats are not affected
+	if !q.Submit(2, func() {}) {
+		t.Error("job for another chat was rejected")
+	}
+
+	unblock()
+	if err := q.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Once drained, the chat accepts jobs again
+	if !q.Submit(1, func() {}) {
+		t.Error("job after draining was rejected")
+	}
+}
+
+func TestChatQueueDrainTimeout(t *testing.T) {
+	q := newChatQueue(5)
+	unblock := blockChat(t, q, 1)
+	defer unblock()
+
+	ran := make(chan struct{})
+	if !q.Submit(1, func() { close(ran) }) {
+		t.Fatal("queued job was rejected")
+	}
+
+	q.Close()
+	if q.Submit(1, func() {}) {
+		t.Error("job after Close was accepted")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := q.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait with a stuck job: err = %v, want DeadlineExceeded", err)
+	}
+
+	// Jobs queued before Close still run
+	unblock()
+	if err := q.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-ran:
+	default:
+		t.Error("job queued before Close did not run")
+	}
+}
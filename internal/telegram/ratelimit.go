@@ -0,0 +1,159 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimit bounds agent runs, which cost Gemini and Sheets quota. Zero
+// values disable the corresponding limit.
+type RateLimit struct {
+	// PerMinute is how many runs a user earns per minute, up to Burst
+	// saved for a busy moment (a token bucket per user).
+	PerMinute float64
+	Burst     int
+	// MaxConcurrent caps runs in flight across all users. A run waits up
+	// to QueueWait for a free slot.
+	MaxConcurrent int
+	QueueWait     time.Duration
+}
+
+// RateLimitError is returned when a run is rejected by a limit.
+type RateLimitError struct {
+	Global     bool // the concurrency cap, not the user's own rate
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.Global {
+		return fmt.Sprintf("too many runs in progress, retry after %s", e.RetryAfter)
+	}
+	return fmt.Sprintf("rate limit exceeded, retry after %s", e.RetryAfter)
+}
+
+// maxBuckets triggers dropping the buckets of users who are back to a
+// full bucket, which behave exactly like a missing one.
+const maxBuckets = 1024
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// limiter applies a RateLimit. Users are keyed by their Telegram user ID
+// (see WithSender), or by chat for runs without a sender.
+type limiter struct {
+	limit   RateLimit
+	mu      sync.Mutex
+	buckets map[int64]*bucket
+	slots   chan struct{}
+	now     func() time.Time
+}
+
+func newLimiter(limit RateLimit) *limiter {
+	l := &limiter{
+		limit:   limit,
+		buckets: make(map[int64]*bucket),
+		now:     time.Now,
+	}
+	if limit.MaxConcurrent > 0 {
+		l.slots = make(chan struct{}, limit.MaxConcurrent)
+	}
+	return l
+}
+
+// acquire takes one of the user's tokens (unless charge is false) and a
+// run slot. Call release when the run is over.
+func (l *limiter) acquire(ctx context.Context, userID int64, charge bool) (release func(), err error) {
+	if charge {
+		if wait, ok := l.take(userID); !ok {
+			return nil, &RateLimitError{RetryAfter: wait}
+		}
+	}
+	refund := func() {
+		if charge {
+			l.refund(userID)
+		}
+	}
+	if l.slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+	default:
+		timer := time.NewTimer(l.limit.QueueWait)
+		defer timer.Stop()
+
+		select {
+		case l.slots <- struct{}{}:
+		case <-timer.C:
+			// Not the user's fault; give the token back
+			refund()
+			return nil, &RateLimitError{Global: true, RetryAfter: max(l.limit.QueueWait, time.Second)}
+		case <-ctx.Done():
+			refund()
+			return nil, ctx.Err()
+		}
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-l.slots })
+	}, nil
+}
+
+// take spends one token of userID's bucket. When it is empty, it returns
+// how long until the next token.
+func (l *limiter) take(userID int64) (time.Duration, bool) {
+	if l.limit.PerMinute <= 0 {
+		return 0, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	burst := float64(max(l.limit.Burst, 1))
+	rate := l.limit.PerMinute / 60 // tokens per second
+
+	b, ok := l.buckets[userID]
+	if !ok {
+		l.prune(now, burst, rate)
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[userID] = b
+	}
+
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens < 1 {
+		seconds := math.Ceil((1 - b.tokens) / rate)
+		return time.Duration(seconds) * time.Second, false
+	}
+	b.tokens--
+	return 0, true
+}
+
+func (l *limiter) refund(userID int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if b, ok := l.buckets[userID]; ok {
+		b.tokens = math.Min(float64(max(l.limit.Burst, 1)), b.tokens+1)
+	}
+}
+
+// prune drops full buckets once there are many; l.mu must be held.
+func (l *limiter) prune(now time.Time, burst, rate float64) {
+	if len(l.buckets) < maxBuckets {
+		return
+	}
+	for id, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*rate >= burst {
+			delete(l.buckets, id)
+		}
+	}
+}
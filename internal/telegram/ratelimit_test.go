@@ -0,0 +1,129 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock is a limiter clock moved by hand.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestLimiter(limit RateLimit) (*limiter, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	l := newLimiter(limit)
+	l.now = clock.now
+	return l, clock
+}
+
+// tryRun acquires and immediately releases a run for userID.
+func tryRun(t *testing.T, l *limiter, userID int64) error {
+	t.Helper()
+	release, err := l.acquire(context.Background(), userID, true)
+	if err == nil {
+		release()
+	}
+	return err
+}
+
+func TestLimiterBurstAndRefill(t *testing.T) {
+	l, clock := newTestLimiter(RateLimit{PerMinute: 6, Burst: 3})
+
+	for i := 0; i < 3; i++ {
+		if err := tryRun(t, l, 1); err != nil {
+			t.Fatalf("run %d of the burst: %v", i+1, err)
+		}
+	}
+
+	var limitErr *RateLimitError
+	if err := tryRun(t, l, 1); !errors.As(err, &limitErr) || limitErr.Global {
+		t.Fatalf("run after the burst: err = %v, want a per-user RateLimitError", err)
+	}
+	if limitErr.RetryAfter != 10*time.Second {
+		t.Errorf("RetryAfter = %s, want 10s (6 runs per minute)", limitErr.RetryAfter)
+	}
+
+	// One token every 10 seconds
+	clock.advance(9 * time.Second)
+	if err := tryRun(t, l, 1); err == nil {
+		t.Fatal("run before the next token was allowed")
+	}
+	clock.advance(time.Second)
+	if err := tryRun(t, l, 1); err != nil {
+		t.Fatalf("run after refill: %v", err)
+	}
+	if err := tryRun(t, l, 1); err == nil {
+		t.Fatal("second run after one refill was allowed")
+	}
+
+	// A long pause refills the bucket only up to the burst
+	clock.advance(time.Hour)
+	for i := 0; i < 3; i++ {
+		if err := tryRun(t, l, 1); err != nil {
+			t.Fatalf("run %d after a pause: %v", i+1, err)
+		}
+	}
+	if err := tryRun(t, l, 1); err == nil {
+		t.Fatal("bucket refilled beyond the burst")
+	}
+}
+
+func TestLimiterPerSender(t *testing.T) {
+	l, _ := newTestLimiter(RateLimit{PerMinute: 1, Burst: 1})
+
+	if err := tryRun(t, l, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := tryRun(t, l, 1); err == nil {
+		t.Fatal("sender 1 exceeded the limit")
+	}
+	// Another member of the same group has their own bucket
+	if err := tryRun(t, l, 2); err != nil {
+		t.Errorf("sender 2 was limited by sender 1: %v", err)
+	}
+}
+
+func TestLimiterUnchargedRun(t *testing.T) {
+	l, _ := newTestLimiter(RateLimit{PerMinute: 1, Burst: 1, MaxConcurrent: 1, QueueWait: 10 * time.Millisecond})
+
+	if err := tryRun(t, l, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// A resumed run after a confirmation does not need a token...
+	release, err := l.acquire(context.Background(), 1, false)
+	if err != nil {
+		t.Fatalf("uncharged run with an empty bucket: %v", err)
+	}
+
+	// ...but still takes a run slot
+	var limitErr *RateLimitError
+	if _, err := l.acquire(context.Background(), 2, false); !errors.As(err, &limitErr) || !limitErr.Global {
+		t.Errorf("run while all slots are taken: err = %v, want a global RateLimitError", err)
+	}
+	release()
+}
+
+func TestLimiterConcurrencyRefundsToken(t *testing.T) {
+	l, _ := newTestLimiter(RateLimit{PerMinute: 1, Burst: 1, MaxConcurrent: 1, QueueWait: 10 * time.Millisecond})
+
+	release, err := l.acquire(context.Background(), 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var limitErr *RateLimitError
+	if err := tryRun(t, l, 2); !errors.As(err, &limitErr) || !limitErr.Global {
+		t.Fatalf("run while all slots are taken: err = %v, want a global RateLimitError", err)
+	}
+	release()
+
+	// Sender 2 got their token back, so the next try succeeds
+	if err := tryRun(t, l, 2); err != nil {
+		t.Errorf("run after the slot was freed: %v", err)
+	}
+}
@@ -0,0 +1,293 @@
+// go-agent-tracker/internal/telegram/render.go
+package telegram
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+)
+
+// maxMessageLength is Telegram's limit for one text message, counted in
+// UTF-16 code units of the visible text.
+const maxMessageLength = 4096
+
+// block is a piece of the model's markdown that renders on its own:
+// a paragraph, a fenced code block or a table.
+type block struct {
+	kind  string // "text", "code", "table"
+	lines []string
+}
+
+var (
+	headingPattern = regexp.MustCompile(`^#{1,6}\s+(.*)$`)
+	bulletPattern  = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	linkPattern    = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+	boldPattern    = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	italicPattern  = regexp.MustCompile(`(^|[^\w*])\*([^*\s](?:[^*]*[^*\s])?)\*`)
+	strikePattern  = regexp.MustCompile(`~~(.+?)~~`)
+	tableRule      = regexp.MustCompile(`^:?-+:?$`)
+	numberCell     = regexp.MustCompile(`^[-+]?(Rp\s?)?[\d.,]+%?$`)
+	tagPattern     = regexp.MustCompile(`<[^>]+>`)
+)
+
+// renderHTML converts the model's markdown to Telegram HTML and splits it
+// into messages on paragraph boundaries. Tables become monospace blocks.
+func renderHTML(text string) []string {
+	var rendered []string
+	for _, b := range parseBlocks(text) {
+		rendered = append(rendered, renderBlock(b, maxMessageLength)...)
+	}
+	return pack(rendered, maxMessageLength)
+}
+
+// splitText splits plain text into messages on paragraph boundaries.
+func splitText(text string, limit int) []string {
+	var pieces []string
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		if textLength(paragraph) <= limit {
+			pieces = append(pieces, paragraph)
+			continue
+		}
+		pieces = append(pieces, splitLines(strings.Split(paragraph, "\n"), limit, func(lines []string) string {
+			return strings.Join(lines, "\n")
+		})...)
+	}
+	return pack(pieces, limit)
+}
+
+// plainText strips the tags of a rendered message, for the fallback when
+// Telegram rejects the HTML.
+func plainText(rendered string) string {
+	return html.UnescapeString(tagPattern.ReplaceAllString(rendered, ""))
+}
+
+// textLength is the length Telegram counts: UTF-16 units of visible text.
+func textLength(rendered string) int {
+	return len(utf16.Encode([]rune(plainText(rendered))))
+}
+
+// pack joins pieces into as few messages as fit the limit.
+func pack(pieces []string, limit int) []string {
+	var messages []string
+	current := ""
+	for _, piece := range pieces {
+		if strings.TrimSpace(piece) == "" {
+			continue
+		}
+		if current != "" && textLength(current+"\n\n"+piece) > limit {
+			messages = append(messages, current)
+			current = ""
+		}
+		if current == "" {
+			current = piece
+		} else {
+			current += "\n\n" + piece
+		}
+	}
+	if current != "" {
+		messages = append(messages, current)
+	}
+	return messages
+}
+
+func parseBlocks(text string) []block {
+	var blocks []block
+	var current *block
+
+	flush := func() {
+		if current != nil && len(current.lines) > 0 {
+			blocks = append(blocks, *current)
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if current != nil && current.kind == "code" {
+			if strings.HasPrefix(trimmed, "```") {
+				flush()
+			} else {
+				current.lines = append(current.lines, line)
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			current = &block{kind: "code"}
+		case strings.HasPrefix(trimmed, "|"):
+			if current == nil || current.kind != "table" {
+				flush()
+				current = &block{kind: "table"}
+			}
+			current.lines = append(current.lines, trimmed)
+		case trimmed == "":
+			flush()
+		default:
+			if current == nil || current.kind != "text" {
+				flush()
+				current = &block{kind: "text"}
+			}
+			current.lines = append(current.lines, line)
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+// renderBlock renders b, splitting it by lines if it is over the limit.
+func renderBlock(b block, limit int) []string {
+	render := func(lines []string) string {
+		if b.kind != "text" {
+			return "<pre>" + html.EscapeString(strings.Join(lines, "\n")) + "</pre>"
+		}
+		out := make([]string, len(lines))
+		for i, line := range lines {
+			out[i] = renderLine(line)
+		}
+		return strings.Join(out, "\n")
+	}
+
+	lines := b.lines
+	if b.kind == "table" {
+		lines = formatTable(lines)
+	}
+	return splitLines(lines, limit, render)
+}
+
+// splitLines groups lines into rendered pieces within the limit. A single
+// line that is still too long is cut.
+func splitLines(lines []string, limit int, render func([]string) string) []string {
+	var pieces []string
+	var group []string
+	for _, line := range lines {
+		if textLength(render(append(group, line))) <= limit {
+			group = append(group, line)
+			continue
+		}
+		if len(group) > 0 {
+			pieces = append(pieces, render(group))
+			group = nil
+		}
+		for textLength(render([]string{line})) > limit {
+			runes := []rune(line)
+			cut := limit / 2
+			pieces = append(pieces, render([]string{string(runes[:cut])}))
+			line = string(runes[cut:])
+		}
+		group = []string{line}
+	}
+	if len(group) > 0 {
+		pieces = append(pieces, render(group))
+	}
+	return pieces
+}
+
+// renderLine renders one markdown line: headings become bold, bullets "•".
+func renderLine(line string) string {
+	if m := headingPattern.FindStringSubmatch(line); m != nil {
+		return "<b>" + renderInline(m[1]) + "</b>"
+	}
+	if m := bulletPattern.FindStringSubmatch(line); m != nil {
+		return m[1] + "• " + renderInline(m[2])
+	}
+	return renderInline(line)
+}
+
+// renderInline escapes text and converts inline markdown. Code spans are
+// kept verbatim.
+func renderInline(text string) string {
+	var sb strings.Builder
+	for i, part := range strings.Split(text, "`") {
+		// Odd parts are inside backticks, unless the last one is unclosed
+		if i%2 == 1 && i < strings.Count(text, "`") {
+			sb.WriteString("<code>" + html.EscapeString(part) + "</code>")
+			continue
+		}
+		if i%2 == 1 {
+			sb.WriteString("`")
+		}
+
+		s := html.EscapeString(part)
+		s = linkPattern.ReplaceAllString(s, `<a href="$2">$1</a>`)
+		s = boldPattern.ReplaceAllString(s, "<b>$1$2</b>")
+		s = italicPattern.ReplaceAllString(s, "$1<i>$2</i>")
+		s = strikePattern.ReplaceAllString(s, "<s>$1</s>")
+		sb.WriteString(s)
+	}
+	return sb.String()
+}
+
+// formatTable aligns a markdown table into fixed-width columns, numbers
+// right-aligned, with a rule under the header.
+func formatTable(lines []string) []string {
+	var rows [][]string
+	header := -1
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "|")
+		line = strings.TrimSuffix(line, "|")
+
+		cells := strings.Split(line, "|")
+		isRule := true
+		for i, cell := range cells {
+			cell = strings.TrimSpace(cell)
+			cell = strings.ReplaceAll(cell, "**", "")
+			cell = strings.Trim(cell, "`")
+			cells[i] = cell
+			if !tableRule.MatchString(cell) {
+				isRule = false
+			}
+		}
+		if isRule {
+			if header < 0 && len(rows) > 0 {
+				header = len(rows) - 1
+			}
+			continue
+		}
+		rows = append(rows, cells)
+	}
+
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := len([]rune(cell)); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	var out []string
+	for r, row := range rows {
+		cells := make([]string, len(widths))
+		for i := range widths {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			pad := strings.Repeat(" ", widths[i]-len([]rune(cell)))
+			if r != header && numberCell.MatchString(cell) {
+				cells[i] = pad + cell
+			} else {
+				cells[i] = cell + pad
+			}
+		}
+		out = append(out, strings.TrimRight(strings.Join(cells, "  "), " "))
+
+		if r == header {
+			rules := make([]string, len(widths))
+			for i, w := range widths {
+				rules[i] = strings.Repeat("-", w)
+			}
+			out = append(out, strings.Join(rules, "  "))
+		}
+	}
+	return out
+}
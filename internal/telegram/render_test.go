@@ -0,0 +1,117 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderHTMLInline(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"a < b & c > d", "a &lt; b &amp; c &gt; d"},
+		{"**Total** Rp 45.000", "<b>Total</b> Rp 45.000"},
+		{"*kopi* and ~~teh~~", "<i>kopi</i> and <s>teh</s>"},
+		{"`<script>` stays code", "<code>&lt;script&gt;</code> stays code"},
+		{"unclosed ` tick", "unclosed ` tick"},
+		{"## Summary", "<b>Summary</b>"},
+		{"- Kopi <3", "• Kopi &lt;3"},
+		{"[sheet](https://example.com/a?b=1&c=2)", `<a href="https://example.com/a?b=1&amp;c=2">sheet</a>`},
+	}
+	for _, tt := range tests {
+		got := renderHTML(tt.in)
+		if len(got) != 1 || got[0] != tt.want {
+			t.Errorf("renderHTML(%q) = %q, want [%q]", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRenderHTMLCodeBlockEscapes(t *testing.T) {
+	got := renderHTML("```\nif a < b && c {\n```")
+	want := "<pre>if a &lt; b &amp;&amp; c {</pre>"
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("renderHTML(code) = %q, want [%q]", got, want)
+	}
+}
+
+func TestRenderHTMLTable(t *testing.T) {
+	in := "| Item | Amount |\n|---|---|\n| Kopi | 25.000 |\n| **Nasi goreng** | 5.000 |"
+	want := "<pre>" + strings.Join([]string{
+		"Item         Amount",
+		"-----------  ------",
+		"Kopi         25.000",
+		"Nasi goreng   5.000",
+	}, "\n") + "</pre>"
+
+	got := renderHTML(in)
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("renderHTML(table) =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestRenderHTMLEmpty(t *testing.T) {
+	for _, in := range []string{"", "\n\n", "   ", "```", "```\n```"} {
+		if got := renderHTML(in); len(got) != 0 {
+			t.Errorf("renderHTML(%q) = %q, want no messages", in, got)
+		}
+	}
+}
+
+func TestRenderHTMLSplitsAtLimit(t *testing.T) {
+	// 100 paragraphs of 100 characters are well over one message
+	paragraph := strings.Repeat("x", 99) + "<"
+	var paragraphs []string
+	for range 100 {
+		paragraphs = append(paragraphs, paragraph)
+	}
+
+	got := renderHTML(strings.Join(paragraphs, "\n\n"))
+	if len(got) < 3 {
+		t.Fatalf("got %d messages, want at least 3", len(got))
+	}
+	total := 0
+	for i, msg := range got {
+		if n := textLength(msg); n > maxMessageLength {
+			t.Errorf("message %d is %d long, over %d", i, n, maxMessageLength)
+		}
+		// Paragraph boundaries are kept: no paragraph is cut in two
+		for _, p := range strings.Split(plainText(msg), "\n\n") {
+			if p != paragraph {
+				t.Fatalf("message %d holds a cut paragraph %q", i, p)
+			}
+			total++
+		}
+	}
+	if total != len(paragraphs) {
+		t.Errorf("got %d paragraphs back, want %d", total, len(paragraphs))
+	}
+}
+
+func TestRenderHTMLCutsLongLine(t *testing.T) {
+	line := strings.Repeat("a", 3*maxMessageLength)
+
+	got := renderHTML(line)
+	joined := ""
+	for i, msg := range got {
+		if n := textLength(msg); n > maxMessageLength {
+			t.Errorf("message %d is %d long, over %d", i, n, maxMessageLength)
+		}
+		joined += msg
+	}
+	if len(got) < 3 || strings.ReplaceAll(joined, "\n", "") != line {
+		t.Errorf("long line split into %d messages, text not preserved", len(got))
+	}
+}
+
+func TestSplitTextCountsUTF16(t *testing.T) {
+	// Emoji are two UTF-16 units each, so 3000 of them need two messages
+	got := splitText(strings.Repeat("🧾", 3000), maxMessageLength)
+	if len(got) != 2 {
+		t.Fatalf("got %d messages, want 2", len(got))
+	}
+	for i, msg := range got {
+		if n := textLength(msg); n > maxMessageLength {
+			t.Errorf("message %d is %d long, over %d", i, n, maxMessageLength)
+		}
+	}
+}
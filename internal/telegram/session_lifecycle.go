@@ -0,0 +1,210 @@
+// go-agent-tracker/internal/telegram/session_lifecycle.go
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	trackeragent "finagent/internal/agent"
+	"finagent/internal/agent/hitl"
+
+	"google.golang.org/adk/session"
+)
+
+// SessionPolicy bounds how much history a chat carries into each run.
+type SessionPolicy struct {
+	// IdleTimeout starts a new session after this much silence (0: never).
+	IdleTimeout time.Duration
+	// MaxEvents compacts a session once it holds more events (0: never):
+	// older turns are summarized and only the last KeepEvents stay.
+	MaxEvents  int
+	KeepEvents int
+}
+
+// Summarizer condenses the transcript of compacted turns, merging it with
+// the previous summary. *agent.Summarizer uses the model.
+type Summarizer interface {
+	Summarize(ctx context.Context, previous, transcript string) (string, error)
+}
+
+// maxFallbackSummary bounds the summary kept when no summarizer is set or
+// it fails: the tail of the transcript.
+const maxFallbackSummary = 2000
+
+// SetSessionPolicy enables idle rotation and compaction. summarizer may be
+// nil, which keeps the end of the raw transcript instead.
+func (br *BotRunner) SetSessionPolicy(policy SessionPolicy, summarizer Summarizer) {
+	br.policy = policy
+	br.summarizer = summarizer
+}
+
+// ResetSession starts a new conversation for the chat. Pending
+// confirmations move along so their buttons keep working; user state such
+// as the language is kept. Returns how many confirmations are still pending.
+func (br *BotRunner) ResetSession(ctx context.Context, chatID int64) (int, error) {
+	br.sessionMu.RLock()
+	_, exists := br.sessions[chatID]
+	br.sessionMu.RUnlock()
+	if !exists {
+		return 0, nil
+	}
+
+	sess, err := br.getSession(ctx, chatID)
+	if err != nil {
+		return 0, err
+	}
+	pending := hitl.Pending(sess.State())
+
+	if err := br.rotate(ctx, chatID, sess, carriedState(sess, ""), nil); err != nil {
+		return 0, err
+	}
+	log.Printf("🧹 Chat %d: session reset", chatID)
+	return len(pending), nil
+}
+
+// maintainSession applies the SessionPolicy before a run.
+func (br *BotRunner) maintainSession(ctx context.Context, chatID int64) error {
+	br.sessionMu.RLock()
+	_, exists := br.sessions[chatID]
+	br.sessionMu.RUnlock()
+	if !exists {
+		return nil
+	}
+
+	sess, err := br.getSession(ctx, chatID)
+	if err != nil {
+		return err
+	}
+
+	// Idle: start over, keeping only what is still pending
+	if br.policy.IdleTimeout > 0 && time.Since(sess.LastUpdateTime()) > br.policy.IdleTimeout {
+		if err := br.rotate(ctx, chatID, sess, carriedState(sess, ""), nil); err != nil {
+			return err
+		}
+		log.Printf("💤 Chat %d: idle for %s, started a new session", chatID, time.Since(sess.LastUpdateTime()).Round(time.Minute))
+		return nil
+	}
+
+	events := sess.Events()
+	if br.policy.MaxEvents <= 0 || events.Len() <= br.policy.MaxEvents {
+		return nil
+	}
+
+	all := make([]*session.Event, 0, events.Len())
+	for event := range events.All() {
+		all = append(all, event)
+	}
+
+	// Keep whole turns: the kept part starts at a user message, never
+	// between a tool call and its result
+	start := max(len(all)-br.policy.KeepEvents, 0)
+	for start < len(all) && !isUserTurn(all[start]) {
+		start++
+	}
+	if start == 0 {
+		return nil
+	}
+
+	previous, _ := sess.State().Get(trackeragent.SummaryStateKey)
+	previousText, _ := previous.(string)
+	summary := br.summarize(ctx, previousText, trackeragent.Transcript(all[:start]))
+
+	if err := br.rotate(ctx, chatID, sess, carriedState(sess, summary), all[start:]); err != nil {
+		return err
+	}
+	log.Printf("🗜️ Chat %d: compacted %d events into a summary, kept %d", chatID, start, len(all)-start)
+	return nil
+}
+
+func (br *BotRunner) summarize(ctx context.Context, previous, transcript string) string {
+	if br.summarizer != nil {
+		summary, err := br.summarizer.Summarize(ctx, previous, transcript)
+		if err == nil {
+			return summary
+		}
+		log.Printf("⚠️ Summary failed, keeping the raw transcript: %v", err)
+	}
+
+	summary := transcript
+	if previous != "" {
+		summary = previous + "\n" + transcript
+	}
+	if runes := []rune(summary); len(runes) > maxFallbackSummary {
+		summary = "…" + string(runes[len(runes)-maxFallbackSummary:])
+	}
+	return summary
+}
+
+// rotate replaces the chat's session with a new one holding state and
+// copies of the kept events, then deletes the old session.
+func (br *BotRunner) rotate(ctx context.Context, chatID int64, old session.Session, state map[string]any, keep []*session.Event) error {
+	br.sessionMu.Lock()
+	defer br.sessionMu.Unlock()
+
+	created, err := br.sessionService.Create(ctx, &session.CreateRequest{
+		AppName: "financial_tracker",
+		UserID:  old.UserID(),
+		State:   state,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	for _, event := range keep {
+		// The carried state is already current; don't replay old deltas
+		copied := *event
+		copied.Actions.StateDelta = nil
+		if err := br.sessionService.AppendEvent(ctx, created.Session, &copied); err != nil {
+			return fmt.Errorf("failed to copy event: %w", err)
+		}
+	}
+
+	br.sessions[chatID] = created.Session.ID()
+	if err := br.saveSessionsLocked(); err != nil {
+		return err
+	}
+
+	err = br.sessionService.Delete(ctx, &session.DeleteRequest{
+		AppName:   "financial_tracker",
+		UserID:    old.UserID(),
+		SessionID: old.ID(),
+	})
+	if err != nil {
+		log.Printf("⚠️ Failed to delete old session %s: %v", old.ID(), err)
+	}
+	return nil
+}
+
+// carriedState is the session state a new session starts with: pending
+// confirmations, user state such as the language, and the summary (if
+// any). User state is shared in memory, but only the new journal survives
+// a restart, so it has to be written there too.
+func carriedState(sess session.Session, summary string) map[string]any {
+	state := hitl.PendingState(sess.State())
+	for key, value := range sess.State().All() {
+		if strings.HasPrefix(key, session.KeyPrefixUser) {
+			state[key] = value
+		}
+	}
+	if summary != "" {
+		state[trackeragent.SummaryStateKey] = summary
+	}
+	return state
+}
+
+// isUserTurn reports whether event is a message typed by the user (not a
+// tool result, which ADK also attributes to the user).
+func isUserTurn(event *session.Event) bool {
+	if event.Author != "user" || event.Content == nil {
+		return false
+	}
+	for _, part := range event.Content.Parts {
+		if part.FunctionResponse != nil {
+			return false
+		}
+	}
+	return len(event.Content.Parts) > 0
+}
@@ -0,0 +1,48 @@
+package telegram
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"finagent/internal/i18n"
+	"finagent/internal/sessionstore"
+)
+
+// newDiskRunner opens the sessions journaled in dir, as the bot does on
+// startup.
+func newDiskRunner(t *testing.T, dir string) *BotRunner {
+	t.Helper()
+	ctx := context.Background()
+
+	svc, err := sessionstore.NewFileService(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	br := NewBotRunner(nil, svc, NewToolLogger(t.TempDir()))
+	if err := br.LoadSessions(ctx, filepath.Join(dir, "chats.json")); err != nil {
+		t.Fatal(err)
+	}
+	return br
+}
+
+func TestLanguageSurvivesRotationAndRestart(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	br := newDiskRunner(t, dir)
+	if err := br.SetLanguage(ctx, 42, i18n.Indonesian); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := br.ResetSession(ctx, 42); err != nil {
+		t.Fatal(err)
+	}
+	if got := br.Language(ctx, 42, "en"); got != i18n.Indonesian {
+		t.Fatalf("language after reset = %s, want %s", got, i18n.Indonesian)
+	}
+
+	restarted := newDiskRunner(t, dir)
+	if got := restarted.Language(ctx, 42, "en"); got != i18n.Indonesian {
+		t.Errorf("language after restart = %s, want %s", got, i18n.Indonesian)
+	}
+}
@@ -0,0 +1,148 @@
+// go-agent-tracker/internal/telegram/voice.go
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Transcriber converts a voice note to text, e.g. a local Whisper server.
+// Without one the audio is sent to the model, which understands speech.
+type Transcriber interface {
+	Transcribe(ctx context.Context, path, mimeType string) (string, error)
+}
+
+// SetTranscriber enables transcription before the agent sees voice notes.
+func (tb *TelegramBot) SetTranscriber(t Transcriber) {
+	tb.transcriber = t
+}
+
+// audioTypes are the audio formats the model accepts, with the extension
+// used for the temp file. Telegram voice notes are always OGG/Opus.
+var audioTypes = map[string]string{
+	"audio/ogg":   ".ogg",
+	"audio/mpeg":  ".mp3",
+	"audio/mp4":   ".m4a",
+	"audio/x-m4a": ".m4a",
+	"audio/wav":   ".wav",
+	"audio/x-wav": ".wav",
+	"audio/aac":   ".aac",
+	"audio/flac":  ".flac",
+}
+
+// downloadAudio saves the voice note or audio file of msg. It returns nil
+// when msg has neither.
+func (tb *TelegramBot) downloadAudio(ctx context.Context, msg *tgbotapi.Message) (*Attachment, error) {
+	var fileID, mimeType string
+	var size, duration int
+
+	switch {
+	case msg.Voice != nil:
+		fileID, mimeType, size, duration = msg.Voice.FileID, msg.Voice.MimeType, msg.Voice.FileSize, msg.Voice.Duration
+		if mimeType == "" {
+			mimeType = "audio/ogg"
+		}
+	case msg.Audio != nil:
+		fileID, mimeType, size, duration = msg.Audio.FileID, msg.Audio.MimeType, msg.Audio.FileSize, msg.Audio.Duration
+	default:
+		return nil, nil
+	}
+
+	ext, ok := audioTypes[mimeType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errUnsupportedFile, mimeType)
+	}
+	if limit := tb.config.MaxVoiceDuration; limit > 0 && time.Duration(duration)*time.Second > limit {
+		return nil, fmt.Errorf("voice note too long: %ds (max: %s)", duration, limit)
+	}
+	if int64(size) > tb.config.MaxPhotoSize {
+		return nil, fmt.Errorf("file too large: %d bytes (max: %d)", size, tb.config.MaxPhotoSize)
+	}
+
+	path, err := tb.downloadFile(ctx, fileID, "tg_voice_", ext)
+	if err != nil {
+		return nil, err
+	}
+	return &Attachment{Path: path, MimeType: mimeType}, nil
+}
+
+// WhisperTranscriber calls an OpenAI-compatible /v1/audio/transcriptions
+// endpoint (OpenAI, whisper.cpp server, faster-whisper-server).
+type WhisperTranscriber struct {
+	URL      string // e.g. http://localhost:8000/v1/audio/transcriptions
+	APIKey   string
+	Model    string // default "whisper-1"
+	Language string // optional hint, e.g. "id"
+	Client   *http.Client
+}
+
+func NewWhisperTranscriber(url, apiKey string) *WhisperTranscriber {
+	return &WhisperTranscriber{
+		URL:    url,
+		APIKey: apiKey,
+		Model:  "whisper-1",
+		Client: &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+func (w *WhisperTranscriber) Transcribe(ctx context.Context, path, mimeType string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open audio: %w", err)
+	}
+	defer f.Close()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filepath.Base(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return "", fmt.Errorf("failed to read audio: %w", err)
+	}
+	form.WriteField("model", w.Model)
+	if w.Language != "" {
+		form.WriteField("language", w.Language)
+	}
+	form.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if w.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+w.APIKey)
+	}
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("transcription request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("transcription failed: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to parse transcription: %w", err)
+	}
+	return strings.TrimSpace(result.Text), nil
+}
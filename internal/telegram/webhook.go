@@ -0,0 +1,110 @@
+// go-agent-tracker/internal/telegram/webhook.go
+package telegram
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// secretHeader carries the secret_token registered with setWebhook.
+const secretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// WebhookHandler decodes Telegram updates POSTed to it and forwards them to
+// updates. Requests without the configured secret token are rejected; an
+// empty secret is only allowed when nothing is registered with Telegram
+// (see webhookUpdates).
+func (tb *TelegramBot) WebhookHandler(updates chan<- tgbotapi.Update) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		secret := tb.config.WebhookSecret
+		if secret != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get(secretHeader)), []byte(secret)) != 1 {
+			log.Printf("⚠️ Rejected webhook request from %s: bad secret token", r.RemoteAddr)
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&update); err != nil {
+			http.Error(w, "invalid update", http.StatusBadRequest)
+			return
+		}
+
+		select {
+		case <-tb.stopping:
+			// Telegram retries undelivered updates
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		default:
+		}
+
+		select {
+		case updates <- update:
+			w.WriteHeader(http.StatusOK)
+		case <-tb.stopping:
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		case <-r.Context().Done():
+			// Telegram retries undelivered updates
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+		}
+	})
+}
+
+// webhookUpdates starts the HTTP server and, when WebhookURL is set,
+// registers it with Telegram. Without WebhookURL the server only accepts
+// local POSTs, e.g. sample updates sent with curl.
+func (tb *TelegramBot) webhookUpdates() (<-chan tgbotapi.Update, <-chan error, error) {
+	path := tb.config.WebhookPath
+	if path == "" {
+		path = "/telegram/webhook"
+	}
+
+	if tb.config.WebhookURL != "" {
+		// A public endpoint without a secret would accept forged updates
+		// from anyone who finds the URL
+		if tb.config.WebhookSecret == "" {
+			return nil, nil, fmt.Errorf("TELEGRAM_WEBHOOK_SECRET is required when TELEGRAM_WEBHOOK_URL is set")
+		}
+		if err := tb.setWebhook(strings.TrimRight(tb.config.WebhookURL, "/") + path); err != nil {
+			return nil, nil, err
+		}
+	} else if tb.config.WebhookSecret == "" {
+		log.Println("⚠️ TELEGRAM_WEBHOOK_SECRET is empty, local webhook requests are not authenticated")
+	}
+
+	updates := make(chan tgbotapi.Update, 100)
+	mux := http.NewServeMux()
+	mux.Handle(path, tb.WebhookHandler(updates))
+
+	tb.server = &http.Server{Addr: tb.config.WebhookListen, Handler: mux}
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Printf("🌐 Webhook listening on %s%s", tb.config.WebhookListen, path)
+		errs <- tb.server.ListenAndServe()
+	}()
+
+	return updates, errs, nil
+}
+
+// setWebhook registers url with the secret token. The library's
+// WebhookConfig has no secret_token field, so the request is made directly.
+func (tb *TelegramBot) setWebhook(url string) error {
+	params := tgbotapi.Params{"url": url}
+	params.AddNonEmpty("secret_token", tb.config.WebhookSecret)
+
+	if _, err := tb.bot.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("failed to set webhook: %w", err)
+	}
+	log.Printf("✓ Webhook set to %s", url)
+	return nil
+}
@@ -0,0 +1,123 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const testSecret = "test-secret"
+
+func newWebhookBot() *TelegramBot {
+	return &TelegramBot{
+		config:   BotConfig{WebhookSecret: testSecret},
+		stopping: make(chan struct{}),
+	}
+}
+
+func postUpdate(h http.Handler, body []byte, secret string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/telegram/webhook", bytes.NewReader(body))
+	if secret != "" {
+		req.Header.Set(secretHeader, secret)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestWebhookHandlerSamples(t *testing.T) {
+	samples, err := filepath.Glob("../../data/webhook/*.json")
+	if err != nil || len(samples) == 0 {
+		t.Fatalf("no sample updates found: %v", err)
+	}
+
+	for _, path := range samples {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			body, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want tgbotapi.Update
+			if err := json.Unmarshal(body, &want); err != nil {
+				t.Fatal(err)
+			}
+
+			updates := make(chan tgbotapi.Update, 1)
+			h := newWebhookBot().WebhookHandler(updates)
+
+			if rec := postUpdate(h, body, testSecret); rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", rec.Code)
+			}
+			select {
+			case got := <-updates:
+				if got.UpdateID != want.UpdateID || got.Message == nil || got.Message.Text != want.Message.Text {
+					t.Errorf("forwarded update %+v, want %+v", got, want)
+				}
+			default:
+				t.Fatal("update was not forwarded")
+			}
+		})
+	}
+}
+
+func TestWebhookHandlerRejects(t *testing.T) {
+	body, err := os.ReadFile("../../data/webhook/update_message.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		body   []byte
+		secret string
+		stop   bool
+		want   int
+	}{
+		{name: "missing secret", method: http.MethodPost, body: body, want: http.StatusForbidden},
+		{name: "wrong secret", method: http.MethodPost, body: body, secret: "guess", want: http.StatusForbidden},
+		{name: "GET", method: http.MethodGet, secret: testSecret, want: http.StatusMethodNotAllowed},
+		{name: "invalid JSON", method: http.MethodPost, body: []byte("{"), secret: testSecret, want: http.StatusBadRequest},
+		{name: "shutting down", method: http.MethodPost, body: body, secret: testSecret, stop: true, want: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := newWebhookBot()
+			if tt.stop {
+				close(tb.stopping)
+			}
+			updates := make(chan tgbotapi.Update, 1)
+			h := tb.WebhookHandler(updates)
+
+			req := httptest.NewRequest(tt.method, "/telegram/webhook", bytes.NewReader(tt.body))
+			if tt.secret != "" {
+				req.Header.Set(secretHeader, tt.secret)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if len(updates) != 0 {
+				t.Error("rejected update was forwarded")
+			}
+		})
+	}
+}
+
+func TestWebhookRequiresSecretWithURL(t *testing.T) {
+	tb := &TelegramBot{config: BotConfig{
+		WebhookListen: "127.0.0.1:0",
+		WebhookURL:    "https://bot.example.com",
+	}}
+	if _, _, err := tb.webhookUpdates(); err == nil {
+		t.Fatal("webhook started without a secret")
+	}
+}